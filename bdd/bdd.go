@@ -0,0 +1,395 @@
+// Package bdd implements reduced ordered binary decision diagrams (ROBDDs) for vera statements.
+//
+// A BDD represents a boolean function as a directed acyclic graph in which each internal node tests one atomic
+// statement and has a low (false) and high (true) child, and the leaves are the constants false and true. The atomic
+// statements are tested in the same order along every path, and nodes are shared through a unique table so that no two
+// nodes are identical and no node has identical children. Under a fixed variable order this representation is
+// canonical: two Nodes in the same Manager are equal exactly when they represent equivalent functions.
+package bdd
+
+import (
+	"fmt"
+	"github.com/Ro5bert/vera"
+	"math/big"
+	"sort"
+)
+
+// Node is a reference to a node in a Manager. Nodes are only meaningful in the Manager which created them.
+type Node int
+
+// False and True are the terminal nodes, which exist in every Manager.
+const (
+	False Node = 0
+	True  Node = 1
+)
+
+// node is an internal node, testing the variable at the given level of the order.
+type node struct {
+	level int
+	low   Node
+	high  Node
+}
+
+// terminalLevel is the level of the terminal nodes, which is below every variable.
+const terminalLevel = int(^uint(0) >> 1)
+
+// Manager owns a set of BDD nodes over a variable order. The nodes are hash-consed through a unique table, and the
+// results of ITE are cached, so building related functions in one Manager shares work and memory.
+// A Manager is not safe for concurrent use.
+type Manager struct {
+	nodes  []node
+	unique map[node]Node
+	cache  map[[3]Node]Node
+	order  []string
+	levels map[string]int
+}
+
+// New creates a Manager with the given variable order: order[0] is tested first (at the root of each BDD). Atomic
+// statements which are not in the order are added to the end of it when they are first used.
+func New(order []string) *Manager {
+	m := &Manager{
+		nodes:  []node{{terminalLevel, False, False}, {terminalLevel, True, True}},
+		unique: make(map[node]Node),
+		cache:  make(map[[3]Node]Node),
+		levels: make(map[string]int),
+	}
+	for _, name := range order {
+		m.level(name)
+	}
+	return m
+}
+
+// FromStmt creates a Manager with the given variable order and builds the BDD of the given Stmt in it. Atomic
+// statements in the Stmt which are not in the order are added to the end of it in lexicographic order.
+func FromStmt(s vera.Stmt, order []string) (*Manager, Node) {
+	m := New(order)
+	names := vera.TruthFor(s).Names
+	// TruthFor sorts the names in descending order.
+	for i := len(names) - 1; i >= 0; i-- {
+		m.level(names[i])
+	}
+	return m, m.FromStmt(s)
+}
+
+// Order returns the variable order of the Manager.
+func (m *Manager) Order() []string {
+	return append([]string(nil), m.order...)
+}
+
+// level returns the level of the variable with the given name, adding it to the end of the order if necessary.
+func (m *Manager) level(name string) int {
+	if l, ok := m.levels[name]; ok {
+		return l
+	}
+	l := len(m.order)
+	m.order = append(m.order, name)
+	m.levels[name] = l
+	return l
+}
+
+// mk returns the node testing the variable at the given level with the given children, creating it if necessary.
+func (m *Manager) mk(level int, low Node, high Node) Node {
+	if low == high {
+		return low
+	}
+	n := node{level, low, high}
+	if id, ok := m.unique[n]; ok {
+		return id
+	}
+	id := Node(len(m.nodes))
+	m.nodes = append(m.nodes, n)
+	m.unique[n] = id
+	return id
+}
+
+// Size returns the number of nodes reachable from the given node, including terminals.
+func (m *Manager) Size(f Node) int {
+	seen := make(map[Node]bool)
+	var visit func(Node)
+	visit = func(f Node) {
+		if seen[f] {
+			return
+		}
+		seen[f] = true
+		if f > True {
+			visit(m.nodes[f].low)
+			visit(m.nodes[f].high)
+		}
+	}
+	visit(f)
+	return len(seen)
+}
+
+// Var returns the BDD of the atomic statement with the given name.
+func (m *Manager) Var(name string) Node {
+	return m.mk(m.level(name), False, True)
+}
+
+// FromStmt builds the BDD of the given Stmt.
+func (m *Manager) FromStmt(s vera.Stmt) Node {
+	switch s := s.(type) {
+	case vera.Const:
+		if s {
+			return True
+		}
+		return False
+	case vera.Var:
+		return m.Var(string(s))
+	case vera.Not:
+		return m.Not(m.FromStmt(s.X))
+	case vera.Binary:
+		return m.Apply(s.Op, m.FromStmt(s.Left), m.FromStmt(s.Right))
+	case vera.IfThenElse:
+		return m.ITE(m.FromStmt(s.Cond), m.FromStmt(s.Then), m.FromStmt(s.Else))
+	default:
+		panic(fmt.Sprintf("bdd.FromStmt: unexpected Stmt type %T", s))
+	}
+}
+
+// cofactors returns the children of f with respect to the variable at the given level, which must be at or above the
+// level of f.
+func (m *Manager) cofactors(f Node, level int) (Node, Node) {
+	n := m.nodes[f]
+	if n.level != level {
+		return f, f
+	}
+	return n.low, n.high
+}
+
+// ITE returns the BDD of "if f then g else h". Every binary operator can be expressed with it, so it is the core
+// operation on which Apply and Not are built.
+func (m *Manager) ITE(f Node, g Node, h Node) Node {
+	switch {
+	case f == True:
+		return g
+	case f == False:
+		return h
+	case g == h:
+		return g
+	case g == True && h == False:
+		return f
+	}
+	key := [3]Node{f, g, h}
+	if r, ok := m.cache[key]; ok {
+		return r
+	}
+	level := m.nodes[f].level
+	if l := m.nodes[g].level; l < level {
+		level = l
+	}
+	if l := m.nodes[h].level; l < level {
+		level = l
+	}
+	f0, f1 := m.cofactors(f, level)
+	g0, g1 := m.cofactors(g, level)
+	h0, h1 := m.cofactors(h, level)
+	r := m.mk(level, m.ITE(f0, g0, h0), m.ITE(f1, g1, h1))
+	m.cache[key] = r
+	return r
+}
+
+// Not returns the BDD of the negation of f.
+func (m *Manager) Not(f Node) Node {
+	return m.ITE(f, False, True)
+}
+
+// Apply returns the BDD of "f op g".
+func (m *Manager) Apply(op vera.Op, f Node, g Node) Node {
+	switch op {
+	case vera.OpAnd:
+		return m.ITE(f, g, False)
+	case vera.OpOr:
+		return m.ITE(f, True, g)
+	case vera.OpXor:
+		return m.ITE(f, m.Not(g), g)
+	case vera.OpCond:
+		return m.ITE(f, g, True)
+	case vera.OpBicond, vera.OpXnor:
+		return m.ITE(f, g, m.Not(g))
+	case vera.OpNand:
+		return m.ITE(f, m.Not(g), True)
+	case vera.OpNor:
+		return m.ITE(f, False, m.Not(g))
+	default:
+		panic(fmt.Sprintf("bdd.Apply: unexpected operator %v", op))
+	}
+}
+
+// Restrict returns the BDD of f with the atomic statement with the given name replaced by the given value. If the atomic
+// statement is not in the order, f cannot depend on it, so f is returned unchanged.
+func (m *Manager) Restrict(f Node, name string, val bool) Node {
+	level, ok := m.levels[name]
+	if !ok {
+		return f
+	}
+	cache := make(map[Node]Node)
+	var restrict func(Node) Node
+	restrict = func(f Node) Node {
+		n := m.nodes[f]
+		if n.level > level {
+			return f
+		}
+		if r, ok := cache[f]; ok {
+			return r
+		}
+		var r Node
+		switch {
+		case n.level < level:
+			r = m.mk(n.level, restrict(n.low), restrict(n.high))
+		case val:
+			r = n.high
+		default:
+			r = n.low
+		}
+		cache[f] = r
+		return r
+	}
+	return restrict(f)
+}
+
+// Exists returns the BDD of f with the atomic statements with the given names existentially quantified, i.e. the
+// function which is true when f is true for some values of those atomic statements.
+func (m *Manager) Exists(f Node, names ...string) Node {
+	return m.quantify(f, names, vera.OpOr)
+}
+
+// Forall returns the BDD of f with the atomic statements with the given names universally quantified, i.e. the
+// function which is true when f is true for all values of those atomic statements.
+func (m *Manager) Forall(f Node, names ...string) Node {
+	return m.quantify(f, names, vera.OpAnd)
+}
+
+// quantify eliminates the atomic statements with the given names from f by combining the two cofactors of each with
+// the given operator. Names which are not in the order are ignored, since f cannot depend on them; in particular, they
+// are not added to the order, which would change the result of SatCount.
+func (m *Manager) quantify(f Node, names []string, op vera.Op) Node {
+	levels := make(map[int]bool, len(names))
+	for _, name := range names {
+		if l, ok := m.levels[name]; ok {
+			levels[l] = true
+		}
+	}
+	cache := make(map[Node]Node)
+	var quantify func(Node) Node
+	quantify = func(f Node) Node {
+		if f <= True {
+			return f
+		}
+		if r, ok := cache[f]; ok {
+			return r
+		}
+		n := m.nodes[f]
+		low, high := quantify(n.low), quantify(n.high)
+		var r Node
+		if levels[n.level] {
+			r = m.Apply(op, low, high)
+		} else {
+			r = m.mk(n.level, low, high)
+		}
+		cache[f] = r
+		return r
+	}
+	return quantify(f)
+}
+
+// SatCount returns the number of sets of truth values for all the atomic statements in the Manager's order at which f
+// is true.
+func (m *Manager) SatCount(f Node) *big.Int {
+	cache := make(map[Node]*big.Int)
+	// count returns the number of satisfying sets of truth values for the variables at or below the level of f.
+	var count func(Node) *big.Int
+	count = func(f Node) *big.Int {
+		if f <= True {
+			return big.NewInt(int64(f))
+		}
+		if c, ok := cache[f]; ok {
+			return c
+		}
+		n := m.nodes[f]
+		low := m.scale(count(n.low), n.level, m.nodes[n.low].level)
+		high := m.scale(count(n.high), n.level, m.nodes[n.high].level)
+		c := low.Add(low, high)
+		cache[f] = c
+		return c
+	}
+	return m.scale(count(f), -1, m.nodes[f].level)
+}
+
+// scale returns a copy of the count for a node at the given child level multiplied by 2 for each variable strictly
+// between the parent and child levels, since the BDD does not depend on them.
+func (m *Manager) scale(c *big.Int, parent int, child int) *big.Int {
+	if child == terminalLevel {
+		child = len(m.order)
+	}
+	return new(big.Int).Lsh(c, uint(child-parent-1))
+}
+
+// Eval returns the value of f at the given truth values. Atomic statements which are not in the Truth are false.
+func (m *Manager) Eval(f Node, truth vera.Truth) bool {
+	for f > True {
+		n := m.nodes[f]
+		if v, _ := truth.Get(m.order[n.level]); v {
+			f = n.high
+		} else {
+			f = n.low
+		}
+	}
+	return f == True
+}
+
+// Stmt converts f back to a Stmt. Each node becomes an if-then-else on its atomic statement, which is simplified to a
+// conjunction, disjunction, or (negated) atomic statement when one of its children is a terminal. Since shared nodes
+// are expanded once for each path reaching them, the result may be exponentially larger than the BDD.
+func (m *Manager) Stmt(f Node) vera.Stmt {
+	switch f {
+	case False:
+		return vera.Const(false)
+	case True:
+		return vera.Const(true)
+	}
+	n := m.nodes[f]
+	x := vera.Var(m.order[n.level])
+	switch {
+	case n.low == False && n.high == True:
+		return x
+	case n.low == True && n.high == False:
+		return vera.Not{X: x}
+	case n.low == False:
+		return vera.Binary{Op: vera.OpAnd, Left: x, Right: m.Stmt(n.high)}
+	case n.high == True:
+		return vera.Binary{Op: vera.OpOr, Left: x, Right: m.Stmt(n.low)}
+	case n.high == False:
+		return vera.Binary{Op: vera.OpAnd, Left: vera.Not{X: x}, Right: m.Stmt(n.low)}
+	case n.low == True:
+		return vera.Binary{Op: vera.OpOr, Left: vera.Not{X: x}, Right: m.Stmt(n.high)}
+	default:
+		return vera.IfThenElse{Cond: x, Then: m.Stmt(n.high), Else: m.Stmt(n.low)}
+	}
+}
+
+// Support returns the names of the atomic statements on which f depends, in the Manager's order.
+func (m *Manager) Support(f Node) []string {
+	levels := make(map[int]bool)
+	seen := make(map[Node]bool)
+	var visit func(Node)
+	visit = func(f Node) {
+		if f <= True || seen[f] {
+			return
+		}
+		seen[f] = true
+		levels[m.nodes[f].level] = true
+		visit(m.nodes[f].low)
+		visit(m.nodes[f].high)
+	}
+	visit(f)
+	sorted := make([]int, 0, len(levels))
+	for l := range levels {
+		sorted = append(sorted, l)
+	}
+	sort.Ints(sorted)
+	names := make([]string, len(sorted))
+	for i, l := range sorted {
+		names[i] = m.order[l]
+	}
+	return names
+}
@@ -0,0 +1,154 @@
+package bdd
+
+import (
+	"fmt"
+	"github.com/Ro5bert/vera"
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+// randomStmt returns a random Stmt of the given depth over the atomic statements a-e.
+func randomStmt(r *rand.Rand, depth int) vera.Stmt {
+	if depth == 0 || r.Intn(4) == 0 {
+		if r.Intn(10) == 0 {
+			return vera.Const(r.Intn(2) == 0)
+		}
+		return vera.Var(string(rune('a' + r.Intn(5))))
+	}
+	switch r.Intn(6) {
+	case 0:
+		return vera.Not{X: randomStmt(r, depth-1)}
+	case 1:
+		return vera.IfThenElse{Cond: randomStmt(r, depth-1), Then: randomStmt(r, depth-1), Else: randomStmt(r, depth-1)}
+	default:
+		return vera.Binary{Op: vera.Op(r.Intn(int(vera.OpXnor) + 1)), Left: randomStmt(r, depth-1),
+			Right: randomStmt(r, depth-1)}
+	}
+}
+
+func parse(t *testing.T, input string) vera.Stmt {
+	s, _, err := vera.ParseWithOptions(input, vera.Options{Precedence: vera.Standard})
+	if err != nil {
+		t.Fatalf("error occurred while parsing: %v (input: %s)", err, input)
+	}
+	return s
+}
+
+func TestFromStmtRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 500; i++ {
+		s := randomStmt(r, 5)
+		m, f := FromStmt(s, []string{"e", "c", "a"})
+		truth := vera.TruthFor(s)
+		count := int64(0)
+		for truth.Val = 0; truth.Val < 1<<len(truth.Names); truth.Val++ {
+			if m.Eval(f, truth) != s.Eval(truth) {
+				t.Fatalf("BDD of %s differs at %s", s, truth)
+			}
+			if s.Eval(truth) {
+				count++
+			}
+		}
+		// The order always contains a, c, and e, which may not appear in the Stmt.
+		count <<= uint(len(m.Order()) - len(truth.Names))
+		if m.SatCount(f).Cmp(big.NewInt(count)) != 0 {
+			t.Fatalf("expected %d models for %s; got %s", count, s, m.SatCount(f))
+		}
+		if equiv, _ := vera.Equivalent(s, m.Stmt(f)); !equiv {
+			t.Fatalf("expected %s to be equivalent to %s", m.Stmt(f), s)
+		}
+	}
+}
+
+func TestCanonical(t *testing.T) {
+	for _, pair := range [][2]string{
+		{"a > b", "!a | b"},
+		{"!(a & b)", "!a | !b"},
+		{"a ↑ b", "!(a & b)"},
+		{"a ↓ b", "!a & !b"},
+		{"a ⊙ b", "a = b"},
+		{"a ^ b", "!(a = b)"},
+		{"if c then a else b", "(c & a) | (!c & b)"},
+		{"a | !a", "1"},
+		{"(a & b) | (a & !b)", "a"},
+	} {
+		m := New(nil)
+		a, b := m.FromStmt(parse(t, pair[0])), m.FromStmt(parse(t, pair[1]))
+		if a != b {
+			t.Fatalf("expected '%s' and '%s' to have the same BDD", pair[0], pair[1])
+		}
+	}
+	m := New(nil)
+	if m.FromStmt(parse(t, "a & b")) == m.FromStmt(parse(t, "a | b")) {
+		t.Fatal("expected 'a & b' and 'a | b' to have different BDDs")
+	}
+}
+
+func TestRestrictAndQuantify(t *testing.T) {
+	m := New(nil)
+	f := m.FromStmt(parse(t, "(a & b) | (!a & c)"))
+	if m.Restrict(f, "a", true) != m.Var("b") {
+		t.Fatal("expected restricting a to 1 to give b")
+	}
+	if m.Restrict(f, "a", false) != m.Var("c") {
+		t.Fatal("expected restricting a to 0 to give c")
+	}
+	if m.Exists(f, "a") != m.FromStmt(parse(t, "b | c")) {
+		t.Fatal("expected exists a to give b | c")
+	}
+	if m.Forall(f, "a") != m.FromStmt(parse(t, "b & c")) {
+		t.Fatal("expected forall a to give b & c")
+	}
+	if m.Exists(f, "a", "b", "c") != True || m.Forall(f, "a", "b", "c") != False {
+		t.Fatal("expected quantifying every atomic to give a constant")
+	}
+	if s := m.Support(m.Exists(f, "b")); len(s) != 2 || s[0] != "a" || s[1] != "c" {
+		t.Fatalf("expected support [a c]; got %v", s)
+	}
+}
+
+func TestRestrictAndQuantifyUnknown(t *testing.T) {
+	m := New(nil)
+	f := m.FromStmt(parse(t, "a | b"))
+	if m.Restrict(f, "z", true) != f || m.Exists(f, "z") != f || m.Forall(f, "y", "z") != f {
+		t.Fatal("expected restricting or quantifying an atomic which is not in the order to leave f unchanged")
+	}
+	if order := m.Order(); len(order) != 2 {
+		t.Fatalf("expected the order to be unchanged; got %v", order)
+	}
+	if m.SatCount(f).Int64() != 3 {
+		t.Fatalf("expected 3 models; got %v", m.SatCount(f))
+	}
+}
+
+func TestOrder(t *testing.T) {
+	s := parse(t, "(a1 & b1) | (a2 & b2) | (a3 & b3)")
+	interleaved, f := FromStmt(s, []string{"a1", "b1", "a2", "b2", "a3", "b3"})
+	separated, g := FromStmt(s, []string{"a1", "a2", "a3", "b1", "b2", "b3"})
+	if interleaved.Size(f) != 8 {
+		t.Fatalf("expected 8 nodes with interleaved order; got %d", interleaved.Size(f))
+	}
+	if separated.Size(g) <= interleaved.Size(f) {
+		t.Fatalf("expected separated order to need more nodes; got %d", separated.Size(g))
+	}
+	if interleaved.SatCount(f).Cmp(separated.SatCount(g)) != 0 {
+		t.Fatal("expected the model count not to depend on the order")
+	}
+}
+
+func TestSatCountWide(t *testing.T) {
+	// a0 ^ a1 ^ ... ^ a99 is true for exactly half of the 2^100 sets of truth values.
+	m := New(nil)
+	f := False
+	for i := 0; i < 100; i++ {
+		f = m.Apply(vera.OpXor, f, m.Var(fmt.Sprintf("a%d", i)))
+	}
+	expected := new(big.Int).Lsh(big.NewInt(1), 99)
+	if m.SatCount(f).Cmp(expected) != 0 {
+		t.Fatalf("expected %s models; got %s", expected, m.SatCount(f))
+	}
+	if m.Size(f) != 2*100+1 {
+		t.Fatalf("expected %d nodes; got %d", 2*100+1, m.Size(f))
+	}
+}
@@ -0,0 +1,57 @@
+package vera
+
+// BitSet is a growable bit field. It backs the truth values of a Truth with more than 64 atomic statements, where a
+// single uint64 is not wide enough. The zero value is an empty set in which every bit is zero.
+type BitSet []uint64
+
+// Get returns whether the ith bit is set. Bits beyond the current length of the set are zero.
+func (b BitSet) Get(i int) bool {
+	w := i / 64
+	if w >= len(b) {
+		return false
+	}
+	return b[w]&(1<<uint(i%64)) > 0
+}
+
+// Set sets the ith bit to the given value, growing the set as necessary.
+func (b *BitSet) Set(i int, val bool) {
+	w := i / 64
+	if w >= len(*b) {
+		if !val {
+			// Bits beyond the end are already zero; no need to grow.
+			return
+		}
+		grown := make(BitSet, w+1)
+		copy(grown, *b)
+		*b = grown
+	}
+	if val {
+		(*b)[w] |= 1 << uint(i%64)
+	} else {
+		(*b)[w] &^= 1 << uint(i%64)
+	}
+}
+
+// Clone returns a copy of the set which does not share memory with the original.
+func (b BitSet) Clone() BitSet {
+	if b == nil {
+		return nil
+	}
+	c := make(BitSet, len(b))
+	copy(c, b)
+	return c
+}
+
+// subsetOf returns whether every bit set in b is also set in o.
+func (b BitSet) subsetOf(o BitSet) bool {
+	for w, bits := range b {
+		var other uint64
+		if w < len(o) {
+			other = o[w]
+		}
+		if bits&^other != 0 {
+			return false
+		}
+	}
+	return true
+}
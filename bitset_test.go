@@ -0,0 +1,31 @@
+package vera
+
+import "testing"
+
+func TestBitSet(t *testing.T) {
+	var b BitSet
+	for _, i := range []int{0, 63, 64, 200} {
+		if b.Get(i) {
+			t.Fatalf("expected bit %d to be unset in empty set", i)
+		}
+		b.Set(i, true)
+		if !b.Get(i) {
+			t.Fatalf("expected bit %d to be set", i)
+		}
+	}
+	if len(b) != 4 {
+		t.Fatalf("expected set to grow to 4 words; got %d", len(b))
+	}
+	c := b.Clone()
+	b.Set(64, false)
+	if b.Get(64) {
+		t.Fatal("expected bit 64 to be unset")
+	}
+	if !c.Get(64) {
+		t.Fatal("expected clone to be unaffected by changes to the original")
+	}
+	b.Set(1000, false)
+	if len(b) != 4 {
+		t.Fatalf("expected unsetting a bit beyond the end not to grow the set; got %d words", len(b))
+	}
+}
@@ -0,0 +1,171 @@
+package vera
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+// maxTruthTableAtomics is the largest number of atomic statements for which NewTruthTable builds a TruthTable; a table
+// for this many takes 2^32 bits (512 MiB).
+const maxTruthTableAtomics = 32
+
+// lowVarWords are the values of the first six atomic statements of a Truth in the 64 consecutive rows of a word of a
+// TruthTable: bit r of lowVarWords[i] is bit i of r.
+var lowVarWords = [6]uint64{
+	0xAAAAAAAAAAAAAAAA,
+	0xCCCCCCCCCCCCCCCC,
+	0xF0F0F0F0F0F0F0F0,
+	0xFF00FF00FF00FF00,
+	0xFFFF0000FFFF0000,
+	0xFFFFFFFF00000000,
+}
+
+// wordFunc computes word w of the output column of a truth table, i.e. the values at the sets of truth values with
+// Truth.Val from 64*w to 64*w+63, with the value at Truth.Val == 64*w+r in bit r.
+type wordFunc func(w uint64) uint64
+
+// compileWords compiles the given Stmt into a wordFunc over the atomic statements of the given Truth, so that a whole
+// truth table costs one walk of the compiled Stmt per 64 rows instead of one per row.
+func compileWords(s Stmt, truth Truth) (wordFunc, error) {
+	switch s := s.(type) {
+	case Const:
+		var word uint64
+		if s {
+			word = ^uint64(0)
+		}
+		return func(uint64) uint64 { return word }, nil
+	case Var:
+		i, ok := truth.shiftMap[string(s)]
+		if !ok {
+			return nil, fmt.Errorf("atomic statement %s is not in the truth table", s)
+		}
+		if i < len(lowVarWords) {
+			word := lowVarWords[i]
+			return func(uint64) uint64 { return word }, nil
+		}
+		// Higher atomic statements are constant across each word, so they are taken from the word index instead.
+		shift := uint(i - len(lowVarWords))
+		return func(w uint64) uint64 { return -(w >> shift & 1) }, nil
+	case Not:
+		x, err := compileWords(s.X, truth)
+		if err != nil {
+			return nil, err
+		}
+		return func(w uint64) uint64 { return ^x(w) }, nil
+	case Binary:
+		left, err := compileWords(s.Left, truth)
+		if err != nil {
+			return nil, err
+		}
+		right, err := compileWords(s.Right, truth)
+		if err != nil {
+			return nil, err
+		}
+		op := opInfo[s.Op].w
+		return func(w uint64) uint64 { return op(left(w), right(w)) }, nil
+	case IfThenElse:
+		cond, err := compileWords(s.Cond, truth)
+		if err != nil {
+			return nil, err
+		}
+		then, err := compileWords(s.Then, truth)
+		if err != nil {
+			return nil, err
+		}
+		els, err := compileWords(s.Else, truth)
+		if err != nil {
+			return nil, err
+		}
+		return func(w uint64) uint64 {
+			c := cond(w)
+			return c&then(w) | ^c&els(w)
+		}, nil
+	default:
+		panic(fmt.Sprintf("vera.compileWords: unexpected Stmt type %T", s))
+	}
+}
+
+// TruthTable is the output column of the truth table of a Stmt, stored as a bit vector with 64 rows per machine word.
+// Row r is the set of truth values with Truth.Val == r, over the same atomic statements as the Truth the table was made
+// with.
+type TruthTable struct {
+	// Names are the names of the atomic statements, in the same (descending) order as Truth.Names.
+	Names []string
+
+	words []uint64
+}
+
+// NewTruthTable evaluates the given Stmt at every set of truth values of the atomic statements in the given Truth,
+// which must include every atomic statement in the Stmt (e.g. the Truth returned by Parse or TruthFor). Each atomic
+// statement becomes a fixed bit pattern, so the Stmt is only evaluated once for every 64 rows. The Truth may have at
+// most 32 atomic statements.
+func NewTruthTable(s Stmt, truth Truth) (*TruthTable, error) {
+	n := len(truth.Names)
+	if n > maxTruthTableAtomics {
+		return nil, fmt.Errorf("cannot make a truth table with more than %d atomics", maxTruthTableAtomics)
+	}
+	f, err := compileWords(s, truth)
+	if err != nil {
+		return nil, err
+	}
+	t := &TruthTable{Names: truth.Names, words: make([]uint64, (uint64(1)<<n+63)/64)}
+	for w := range t.words {
+		t.words[w] = f(uint64(w))
+	}
+	if n < len(lowVarWords) {
+		// The only word is partially filled; clear the bits past the last row.
+		t.words[0] &= uint64(1)<<(1<<n) - 1
+	}
+	return t, nil
+}
+
+// Rows returns the number of rows in the table, i.e. 2^len(t.Names).
+func (t *TruthTable) Rows() uint64 {
+	return uint64(1) << len(t.Names)
+}
+
+// Get returns the value of the Stmt at the given row.
+func (t *TruthTable) Get(row uint64) bool {
+	return t.words[row/64]&(1<<(row%64)) > 0
+}
+
+// Count returns the number of rows at which the Stmt is true.
+func (t *TruthTable) Count() uint64 {
+	var count uint64
+	for _, word := range t.words {
+		count += uint64(bits.OnesCount64(word))
+	}
+	return count
+}
+
+// First returns the first row at which the Stmt has the given value, and false if there is no such row.
+func (t *TruthTable) First(val bool) (uint64, bool) {
+	for w, word := range t.words {
+		if !val {
+			word = ^word
+		}
+		if word != 0 {
+			row := uint64(w)*64 + uint64(bits.TrailingZeros64(word))
+			return row, row < t.Rows()
+		}
+	}
+	return 0, false
+}
+
+// Equal reports whether the two tables have the same atomic statements and the same value at every row.
+func (t *TruthTable) Equal(o *TruthTable) bool {
+	if len(t.Names) != len(o.Names) {
+		return false
+	}
+	for i, name := range t.Names {
+		if o.Names[i] != name {
+			return false
+		}
+	}
+	for w, word := range t.words {
+		if o.words[w] != word {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,99 @@
+package vera
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestTruthTableRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		s := randomStmt(r, 5)
+		truth := TruthFor(s)
+		if i%2 == 0 {
+			// Add unused atomic statements so the table spans several words and a-e are not all in the low six bits.
+			truth = TruthFor(s, Var("f"), Var("g"), Var("h"), Var("i"), Var("j"))
+		}
+		table, err := NewTruthTable(s, truth)
+		if err != nil {
+			t.Fatalf("error occurred while making truth table for %s: %v", s, err)
+		}
+		if table.Rows() != 1<<len(truth.Names) {
+			t.Fatalf("expected %d rows for %s; got %d", 1<<len(truth.Names), s, table.Rows())
+		}
+		var count uint64
+		for truth.Val = 0; truth.Val < table.Rows(); truth.Val++ {
+			if table.Get(truth.Val) != s.Eval(truth) {
+				t.Fatalf("expected %t for %s at row %d", s.Eval(truth), s, truth.Val)
+			}
+			if s.Eval(truth) {
+				count++
+			}
+		}
+		if table.Count() != count {
+			t.Fatalf("expected count %d for %s; got %d", count, s, table.Count())
+		}
+	}
+}
+
+func TestTruthTableFirst(t *testing.T) {
+	cases := []struct {
+		input      string
+		firstTrue  int64 // -1 if there is no such row
+		firstFalse int64
+	}{
+		{"a & b", 3, 0},
+		{"a | !a", 0, -1},
+		{"a & !a", -1, 0},
+		{"(a & b) & (c & (d & (e & (f & g))))", 127, 0},
+	}
+	for _, c := range cases {
+		stmt, truth, err := Parse(c.input)
+		if err != nil {
+			t.Fatalf("error occurred while parsing: %v (input: %s)", err, c.input)
+		}
+		table, err := NewTruthTable(stmt, truth)
+		if err != nil {
+			t.Fatalf("error occurred while making truth table: %v (input: %s)", err, c.input)
+		}
+		for _, val := range []bool{true, false} {
+			expected := c.firstTrue
+			if !val {
+				expected = c.firstFalse
+			}
+			row, ok := table.First(val)
+			if ok != (expected >= 0) || (ok && int64(row) != expected) {
+				t.Fatalf("expected first %t row %d for %s; got %d (%t)", val, expected, c.input, row, ok)
+			}
+		}
+	}
+}
+
+func TestTruthTableEqual(t *testing.T) {
+	a, truth, err := Parse("a > b")
+	if err != nil {
+		t.Fatalf("error occurred while parsing: %v", err)
+	}
+	tableA, _ := NewTruthTable(a, truth)
+	tableB, _ := NewTruthTable(Binary{OpOr, Not{Var("a")}, Var("b")}, truth)
+	tableC, _ := NewTruthTable(Binary{OpCond, Var("b"), Var("a")}, truth)
+	if !tableA.Equal(tableB) {
+		t.Fatalf("expected a > b and !a | b to have equal truth tables")
+	}
+	if tableA.Equal(tableC) {
+		t.Fatalf("expected a > b and b > a to have different truth tables")
+	}
+}
+
+func TestTruthTableErrors(t *testing.T) {
+	if _, err := NewTruthTable(Var("x"), TruthFor(Var("a"))); err == nil {
+		t.Fatalf("expected error for atomic statement missing from the Truth")
+	}
+	var s Stmt = Var("a0")
+	for i := 1; i <= maxTruthTableAtomics; i++ {
+		s = Binary{OpAnd, s, Var("a" + string(rune('0'+i/10)) + string(rune('0'+i%10)))}
+	}
+	if _, err := NewTruthTable(s, TruthFor(s)); err == nil {
+		t.Fatalf("expected error for %d atomics", maxTruthTableAtomics+1)
+	}
+}
@@ -0,0 +1,72 @@
+package vera
+
+// Classification is the classification of a Stmt according to the values it takes over all sets of truth values.
+type Classification byte
+
+const (
+	// Contradiction means the Stmt is false for every set of truth values.
+	Contradiction Classification = iota
+	// Contingent means the Stmt is true for some sets of truth values and false for others.
+	Contingent
+	// Tautology means the Stmt is true for every set of truth values.
+	Tautology
+)
+
+func (c Classification) String() string {
+	switch c {
+	case Contradiction:
+		return "contradiction"
+	case Contingent:
+		return "contingent"
+	case Tautology:
+		return "tautology"
+	default:
+		panic("Classification not added to String method!")
+	}
+}
+
+// maxEnumAtomics is the largest number of atomic statements for which analyses enumerate every set of truth values
+// rather than using the SAT solver.
+const maxEnumAtomics = 16
+
+// Classify reports whether the given Stmt is a tautology, a contradiction, or contingent.
+func Classify(s Stmt) Classification {
+	c, _, _ := ClassifyWitness(s)
+	return c
+}
+
+// ClassifyWitness is like Classify, but also returns a set of truth values at which the Stmt is true (if it is not a
+// contradiction) and a set of truth values at which it is false (if it is not a tautology). A witness which does not
+// exist is returned with every truth value set to false.
+// Stmts with few atomic statements are classified from their TruthTable; larger Stmts are classified with two calls to
+// Satisfiable.
+func ClassifyWitness(s Stmt) (c Classification, whenTrue Truth, whenFalse Truth) {
+	truth := TruthFor(s)
+	if len(truth.Names) > maxEnumAtomics {
+		whenTrue, okTrue := Satisfiable(s)
+		whenFalse, okFalse := Satisfiable(Not{s})
+		return classification(okTrue, okFalse), whenTrue, whenFalse
+	}
+	table, err := NewTruthTable(s, truth)
+	if err != nil {
+		// Not possible: maxEnumAtomics is less than maxTruthTableAtomics and the Truth is made from the Stmt.
+		panic(err)
+	}
+	whenTrue, whenFalse = truth, truth
+	var okTrue, okFalse bool
+	whenTrue.Val, okTrue = table.First(true)
+	whenFalse.Val, okFalse = table.First(false)
+	return classification(okTrue, okFalse), whenTrue, whenFalse
+}
+
+// classification returns the Classification of a Stmt given whether it is ever true and whether it is ever false.
+func classification(everTrue bool, everFalse bool) Classification {
+	switch {
+	case !everTrue:
+		return Contradiction
+	case !everFalse:
+		return Tautology
+	default:
+		return Contingent
+	}
+}
@@ -0,0 +1,68 @@
+package vera
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestClassify(t *testing.T) {
+	type testCase struct {
+		input    string
+		expected Classification
+	}
+	for _, c := range []testCase{
+		{"a | !a", Tautology},
+		{"a & !a", Contradiction},
+		{"a > b", Contingent},
+		{"(a > b) = (!b > !a)", Tautology},
+		{"1", Tautology},
+		{"0", Contradiction},
+		{"if a then b else !b", Contingent},
+	} {
+		stmt, _, err := Parse(c.input)
+		if err != nil {
+			t.Fatalf("error occurred while parsing: %v (input: %s)", err, c.input)
+		}
+		class, whenTrue, whenFalse := ClassifyWitness(stmt)
+		if class != c.expected {
+			t.Fatalf("expected %s; got %s (input: %s)", c.expected, class, c.input)
+		}
+		if class != Contradiction && !stmt.Eval(whenTrue) {
+			t.Fatalf("expected %s to be true at %s", c.input, whenTrue)
+		}
+		if class != Tautology && stmt.Eval(whenFalse) {
+			t.Fatalf("expected %s to be false at %s", c.input, whenFalse)
+		}
+	}
+}
+
+func TestClassifyWide(t *testing.T) {
+	// (x0 | x1 | ... | x39) | !(x0 | x1 | ... | x39) is a tautology with too many atomics to enumerate.
+	var sb strings.Builder
+	sb.WriteString("x0")
+	for i := 1; i < 40; i++ {
+		fmt.Fprintf(&sb, " | x%d", i)
+	}
+	disj := sb.String()
+	for _, c := range []struct {
+		input    string
+		expected Classification
+	}{
+		{"(" + disj + ") | !(" + disj + ")", Tautology},
+		{"(" + disj + ") & !(" + disj + ")", Contradiction},
+		{disj, Contingent},
+	} {
+		stmt, _, err := ParseWithOptions(c.input, Options{Precedence: Standard})
+		if err != nil {
+			t.Fatalf("error occurred while parsing: %v", err)
+		}
+		class, whenTrue, whenFalse := ClassifyWitness(stmt)
+		if class != c.expected {
+			t.Fatalf("expected %s; got %s", c.expected, class)
+		}
+		if class == Contingent && (!stmt.Eval(whenTrue) || stmt.Eval(whenFalse)) {
+			t.Fatalf("invalid witnesses %s and %s", whenTrue, whenFalse)
+		}
+	}
+}
@@ -1,9 +1,13 @@
 package main
 
 import (
+	"errors"
+	"fmt"
 	"github.com/Ro5bert/vera"
 	"github.com/spf13/cobra"
+	"io"
 	"os"
+	"strings"
 )
 
 var rootCmd = &cobra.Command{
@@ -11,16 +15,164 @@ var rootCmd = &cobra.Command{
 }
 
 var ttCmd = &cobra.Command{
-	Use: "tt",
+	Use:   "tt",
 	Short: "Generate a truth table for the given logical expression",
+	Long: "Generate a truth table for the given logical expression. If several expressions are given, they share one " +
+		"table with a column for each, over the union of their atomic statements.",
 	RunE: tt,
+	Args: cobra.MinimumNArgs(1),
+}
+
+var satCmd = &cobra.Command{
+	Use:   "sat",
+	Short: "Find a set of truth values which satisfies the given logical expression",
+	Long: "Find a set of truth values which satisfies the given logical expression using a SAT solver, without " +
+		"enumerating a truth table. Prints SAT followed by the satisfying truth values, or UNSAT.",
+	RunE: sat,
+	Args: cobra.ExactArgs(1),
+}
+
+var checkCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Classify the given logical expression as a tautology, contradiction, or contingent",
+	Long: "Classify the given logical expression as a tautology (always true), contradiction (never true), or " +
+		"contingent (depends on its atomic statements). For contingent expressions, a set of truth values making the " +
+		"expression true and one making it false are also shown.",
+	RunE: check,
+	Args: cobra.ExactArgs(1),
+}
+
+var equivCmd = &cobra.Command{
+	Use:   "equiv",
+	Short: "Check whether the two given logical expressions are equivalent",
+	Long: "Check whether the two given logical expressions are equivalent (i.e. have the same value for every set of " +
+		"truth values). If they are not, a set of truth values at which they differ is shown.",
+	RunE: equiv,
+	Args: cobra.ExactArgs(2),
+}
+
+var cnfCmd = &cobra.Command{
+	Use:   "cnf",
+	Short: "Convert the given logical expression to conjunctive normal form",
+	RunE:  cnf,
+	Args:  cobra.ExactArgs(1),
+}
+
+var dnfCmd = &cobra.Command{
+	Use:   "dnf",
+	Short: "Convert the given logical expression to disjunctive normal form",
+	RunE:  dnf,
+	Args:  cobra.ExactArgs(1),
+}
+
+var minCmd = &cobra.Command{
+	Use:   "min",
+	Short: "Find a minimal sum-of-products form of the given logical expression",
+	Long: "Find a minimal sum-of-products form of the given logical expression using the Quine-McCluskey method and " +
+		"Petrick's method. Prints the result followed by the prime implicants it is made of, each as a pattern of " +
+		"1 (true), 0 (false), or - (absent) for each atomic statement. For expressions with many atomic statements, " +
+		"--heuristic finds a cover which is usually minimal or close to it much more quickly.",
+	RunE: minimize,
+	Args: cobra.ExactArgs(1),
+}
+
+var kmapCmd = &cobra.Command{
+	Use:   "kmap",
+	Short: "Generate a Karnaugh map for the given logical expression",
+	Long: "Generate a Karnaugh map for the given logical expression, which must have 2 to 6 atomic statements. With " +
+		"--groups, the cells of each prime implicant in a minimal sum-of-products form are marked with a letter.",
+	RunE: kmap,
+	Args: cobra.ExactArgs(1),
+}
+
+var importCmd = &cobra.Command{
+	Use:   "import [file]",
+	Short: "Convert a CNF in DIMACS format to a logical expression",
+	Long: "Convert a CNF in the DIMACS format used by SAT solvers to a logical expression. The CNF is read from the " +
+		"given file, or from standard input if no file (or '-') is given.",
+	RunE: importCNF,
+	Args: cobra.MaximumNArgs(1),
+}
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Convert the given logical expression to another format",
+	Long: "Convert the given logical expression to another format. The only format is 'dimacs', the DIMACS CNF " +
+		"format used by SAT solvers; the expression is converted with the Tseitin transformation first, so the " +
+		"result is equisatisfiable with it.",
+	RunE: export,
 	Args: cobra.ExactArgs(1),
 }
 
+var countCmd = &cobra.Command{
+	Use:   "count",
+	Short: "Count the sets of truth values which satisfy the given logical expression",
+	Long: "Count the sets of truth values which satisfy the given logical expression, without enumerating a truth " +
+		"table. With --list, the satisfying sets of truth values are also printed one per line as they are found.",
+	RunE: count,
+	Args: cobra.ExactArgs(1),
+}
+
+var genCmd = &cobra.Command{
+	Use:   "gen",
+	Short: "Generate source code which evaluates the given logical expression",
+}
+
+var genGoCmd = &cobra.Command{
+	Use:   "go",
+	Short: "Generate a Go function which evaluates the given logical expression",
+	Long: "Generate a Go function which evaluates the given logical expression, with a bool argument for each atomic " +
+		"statement (or a struct argument, with --struct). With --package, the output is a complete source file, so " +
+		"the command can be used with go:generate (e.g. //go:generate vera gen go --package=rules --out=rule.go " +
+		"'a & b').",
+	RunE: genGo,
+	Args: cobra.ExactArgs(1),
+}
+
+// addTableFlags adds the flags which control the appearance of tables to the given command.
+func addTableFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("no-color", false, "do not colorize the output")
+	cmd.Flags().Bool("ascii", false, "use ASCII characters to draw the table")
+}
+
 func init() {
-	ttCmd.Flags().Bool("no-color", false, "do not colorize the output")
-	ttCmd.Flags().Bool("ascii", false, "use ASCII characters to draw the table")
+	addTableFlags(ttCmd)
+	ttCmd.Flags().Bool("steps", false, "add a column for each subexpression of the expression")
+	ttCmd.Flags().Bool("diff", false, "mark the rows where several expressions do not all have the same value")
+	ttCmd.Flags().String("format", "text",
+		"output format: 'text' (a box-drawn table), 'csv', 'json', 'markdown', 'latex', or 'html'")
+	addTableFlags(equivCmd)
+	rootCmd.PersistentFlags().String("precedence", "strict",
+		"binary operator precedence: 'strict' (all equal; chains must be parenthesized) or 'standard'")
 	rootCmd.AddCommand(ttCmd)
+	rootCmd.AddCommand(satCmd)
+	rootCmd.AddCommand(checkCmd)
+	rootCmd.AddCommand(equivCmd)
+	cnfCmd.Flags().Bool("tseitin", false,
+		"use the Tseitin transformation, which introduces new atomic statements to avoid exponential blowup; the "+
+			"result is equisatisfiable with, rather than equivalent to, the expression")
+	rootCmd.AddCommand(cnfCmd)
+	rootCmd.AddCommand(dnfCmd)
+	minCmd.Flags().Bool("heuristic", false,
+		"use an Espresso-style heuristic minimizer, which is much faster for expressions with many atomic statements "+
+			"but may not find a minimal result")
+	rootCmd.AddCommand(minCmd)
+	addTableFlags(kmapCmd)
+	kmapCmd.Flags().Bool("groups", false, "mark the prime implicants of a minimal sum-of-products form")
+	rootCmd.AddCommand(kmapCmd)
+	countCmd.Flags().Bool("list", false, "also print each satisfying set of truth values")
+	rootCmd.AddCommand(countCmd)
+	rootCmd.AddCommand(importCmd)
+	exportCmd.Flags().String("to", "dimacs", "output format: 'dimacs'")
+	rootCmd.AddCommand(exportCmd)
+	genGoCmd.Flags().String("func", "Eval", "name of the generated function")
+	genGoCmd.Flags().String("package", "", "generate a complete source file in the given package")
+	genGoCmd.Flags().String("struct", "", "take the truth values as a struct type with the given name")
+	genGoCmd.Flags().String("out", "", "write the code to the given file instead of standard output")
+	genCmd.AddCommand(genGoCmd)
+	rootCmd.AddCommand(genCmd)
+	addTableFlags(replCmd)
+	rootCmd.AddCommand(replCmd)
 }
 
 func main() {
@@ -30,7 +182,9 @@ func main() {
 	}
 }
 
-func tt(cmd *cobra.Command, args []string) error {
+// tableStyle returns the CharSet and colorize parameter for rendering a table, as specified by the flags added by
+// addTableFlags.
+func tableStyle(cmd *cobra.Command) (*vera.CharSet, bool) {
 	nocolor, err := cmd.Flags().GetBool("no-color")
 	if err != nil {
 		panic(err)
@@ -39,15 +193,328 @@ func tt(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		panic(err)
 	}
-	stmt, truth, err := vera.Parse(args[0])
+	if ascii {
+		return vera.ASCIIBoxCS, !nocolor
+	}
+	return vera.PrettyBoxCS, !nocolor
+}
+
+func tt(cmd *cobra.Command, args []string) error {
+	var opts vera.TTOptions
+	var err error
+	opts.Steps, err = cmd.Flags().GetBool("steps")
+	if err != nil {
+		panic(err)
+	}
+	opts.Diff, err = cmd.Flags().GetBool("diff")
+	if err != nil {
+		panic(err)
+	}
+	format, err := cmd.Flags().GetString("format")
+	if err != nil {
+		panic(err)
+	}
+	var renderer vera.Renderer
+	if format == "text" {
+		cs, colorize := tableStyle(cmd)
+		renderer = vera.BoxRenderer{CS: cs, Colorize: colorize}
+	} else if r, ok := vera.RendererFor(format); ok {
+		renderer = r
+	} else {
+		return fmt.Errorf("unknown format '%s'; expected 'text', 'csv', 'json', 'markdown', 'latex', or 'html'", format)
+	}
+	stmts := make([]vera.Stmt, len(args))
+	var truth vera.Truth
+	for i, arg := range args {
+		stmts[i], truth, err = parse(cmd, arg)
+		if err != nil {
+			return err
+		}
+	}
+	if len(stmts) > 1 {
+		truth = vera.TruthFor(stmts...)
+	}
+	table, err := vera.NewTable(stmts, truth, opts)
 	if err != nil {
 		return err
 	}
-	var cs *vera.CharSet
-	if ascii {
-		cs = vera.ASCIIBoxCS
+	return renderer.Render(table, os.Stdout)
+}
+
+func sat(cmd *cobra.Command, args []string) error {
+	stmt, _, err := parse(cmd, args[0])
+	if err != nil {
+		return err
+	}
+	truth, ok := vera.Satisfiable(stmt)
+	if !ok {
+		fmt.Println("UNSAT")
+		return nil
+	}
+	fmt.Println("SAT")
+	for _, v := range truthValues(truth) {
+		fmt.Println(v)
+	}
+	return nil
+}
+
+func check(cmd *cobra.Command, args []string) error {
+	stmt, _, err := parse(cmd, args[0])
+	if err != nil {
+		return err
+	}
+	class, whenTrue, whenFalse := vera.ClassifyWitness(stmt)
+	fmt.Println(class)
+	if class == vera.Contingent {
+		fmt.Printf("true:  %s\n", strings.Join(truthValues(whenTrue), ", "))
+		fmt.Printf("false: %s\n", strings.Join(truthValues(whenFalse), ", "))
+	}
+	return nil
+}
+
+func equiv(cmd *cobra.Command, args []string) error {
+	a, _, err := parse(cmd, args[0])
+	if err != nil {
+		return err
+	}
+	b, _, err := parse(cmd, args[1])
+	if err != nil {
+		return err
+	}
+	equivalent, counterexample := vera.Equivalent(a, b)
+	if equivalent {
+		fmt.Println("equivalent")
+		return nil
+	}
+	fmt.Println("not equivalent; counterexample:")
+	cs, colorize := tableStyle(cmd)
+	return vera.RenderRows([]vera.Stmt{a, b}, []vera.Truth{counterexample}, os.Stdout, cs, colorize)
+}
+
+func cnf(cmd *cobra.Command, args []string) error {
+	tseitin, err := cmd.Flags().GetBool("tseitin")
+	if err != nil {
+		panic(err)
+	}
+	stmt, _, err := parse(cmd, args[0])
+	if err != nil {
+		return err
+	}
+	if tseitin {
+		fmt.Println(vera.ToTseitinCNF(stmt))
 	} else {
-		cs = vera.PrettyBoxCS
+		fmt.Println(vera.ToCNF(stmt))
+	}
+	return nil
+}
+
+func dnf(cmd *cobra.Command, args []string) error {
+	stmt, _, err := parse(cmd, args[0])
+	if err != nil {
+		return err
+	}
+	fmt.Println(vera.ToDNF(stmt))
+	return nil
+}
+
+func minimize(cmd *cobra.Command, args []string) error {
+	heuristic, err := cmd.Flags().GetBool("heuristic")
+	if err != nil {
+		panic(err)
+	}
+	stmt, truth, err := parse(cmd, args[0])
+	if err != nil {
+		return err
+	}
+	if len(truth.Names) >= 64 {
+		return errors.New("cannot minimize an expression with 64 or more atomics")
+	}
+	if !heuristic && len(truth.Names) > 32 {
+		return errors.New("cannot exactly minimize an expression with more than 32 atomics; try --heuristic")
+	}
+	var cover vera.Cover
+	if heuristic {
+		cover, truth = vera.HeuristicCover(stmt)
+	} else {
+		cover, truth = vera.MinimalCover(stmt)
+	}
+	fmt.Println(cover.Stmt(truth))
+	if len(cover) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(truth.Names))
+	for i := len(truth.Names) - 1; i >= 0; i-- {
+		names = append(names, truth.Names[i])
+	}
+	fmt.Printf("prime implicants (%s):\n", strings.Join(names, ", "))
+	for _, cube := range cover {
+		fmt.Printf("  %s  %s\n", cube.Pattern(truth), cube.Stmt(truth))
+	}
+	return nil
+}
+
+func kmap(cmd *cobra.Command, args []string) error {
+	groups, err := cmd.Flags().GetBool("groups")
+	if err != nil {
+		panic(err)
+	}
+	stmt, truth, err := parse(cmd, args[0])
+	if err != nil {
+		return err
+	}
+	cs, colorize := tableStyle(cmd)
+	if !groups {
+		return vera.RenderKMap(stmt, truth, os.Stdout, cs, colorize)
+	}
+	if len(truth.Names) < 2 || len(truth.Names) > 6 {
+		// Check before minimizing, which would be slow for many atomics.
+		return errors.New("can only make a Karnaugh map with 2 to 6 atomics")
+	}
+	cover, _ := vera.MinimalCover(stmt)
+	return vera.RenderKMapWithGroups(stmt, truth, cover, os.Stdout, cs, colorize)
+}
+
+func count(cmd *cobra.Command, args []string) error {
+	list, err := cmd.Flags().GetBool("list")
+	if err != nil {
+		panic(err)
+	}
+	stmt, _, err := parse(cmd, args[0])
+	if err != nil {
+		return err
+	}
+	fmt.Println(vera.CountModels(stmt))
+	if list {
+		next := vera.Models(stmt)
+		for truth, ok := next(); ok; truth, ok = next() {
+			fmt.Println(strings.Join(truthValues(truth), ", "))
+		}
+	}
+	return nil
+}
+
+func importCNF(cmd *cobra.Command, args []string) error {
+	in := os.Stdin
+	if len(args) == 1 && args[0] != "-" {
+		f, err := os.Open(args[0])
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+	stmt, err := vera.ReadDIMACS(in)
+	if err != nil {
+		return err
+	}
+	fmt.Println(stmt)
+	return nil
+}
+
+func export(cmd *cobra.Command, args []string) error {
+	to, err := cmd.Flags().GetString("to")
+	if err != nil {
+		panic(err)
+	}
+	if to != "dimacs" {
+		return fmt.Errorf("unknown format '%s'; expected 'dimacs'", to)
+	}
+	stmt, _, err := parse(cmd, args[0])
+	if err != nil {
+		return err
+	}
+	return vera.WriteDIMACS(stmt, os.Stdout)
+}
+
+func genGo(cmd *cobra.Command, args []string) error {
+	funcName, err := cmd.Flags().GetString("func")
+	if err != nil {
+		panic(err)
+	}
+	var opts vera.GoOptions
+	opts.Package, err = cmd.Flags().GetString("package")
+	if err != nil {
+		panic(err)
+	}
+	opts.Struct, err = cmd.Flags().GetString("struct")
+	if err != nil {
+		panic(err)
+	}
+	outName, err := cmd.Flags().GetString("out")
+	if err != nil {
+		panic(err)
+	}
+	stmt, _, err := parse(cmd, args[0])
+	if err != nil {
+		return err
+	}
+	var sb strings.Builder
+	if err := vera.GenerateGoWithOptions(stmt, funcName, &sb, opts); err != nil {
+		return err
+	}
+	if outName == "" {
+		fmt.Print(sb.String())
+		return nil
+	}
+	return os.WriteFile(outName, []byte(sb.String()), 0666)
+}
+
+// truthValues formats each truth value in the given Truth as "name = 0" or "name = 1", in the same (lexicographic)
+// order as the columns of a truth table.
+func truthValues(truth vera.Truth) []string {
+	vals := make([]string, 0, len(truth.Names))
+	for i := len(truth.Names) - 1; i >= 0; i-- {
+		v, _ := truth.Get(truth.Names[i])
+		if v {
+			vals = append(vals, truth.Names[i]+" = 1")
+		} else {
+			vals = append(vals, truth.Names[i]+" = 0")
+		}
+	}
+	return vals
+}
+
+// parseOptions returns the parsing options specified by the persistent flags of the root command.
+func parseOptions(cmd *cobra.Command) (vera.Options, error) {
+	prec, err := cmd.Flags().GetString("precedence")
+	if err != nil {
+		panic(err)
+	}
+	var opts vera.Options
+	switch prec {
+	case "strict":
+		opts.Precedence = vera.Strict
+	case "standard":
+		opts.Precedence = vera.Standard
+	default:
+		return opts, fmt.Errorf("unknown precedence '%s'; expected 'strict' or 'standard'", prec)
+	}
+	return opts, nil
+}
+
+// parse parses the given input with the options specified by the persistent flags of the root command.
+func parse(cmd *cobra.Command, input string) (vera.Stmt, vera.Truth, error) {
+	opts, err := parseOptions(cmd)
+	if err != nil {
+		return nil, vera.Truth{}, err
+	}
+	stmt, truth, err := vera.ParseWithOptions(input, opts)
+	var pe *vera.ParseError
+	if errors.As(err, &pe) {
+		// The arguments were valid as far as cobra is concerned, so don't bury the diagnostic under the usage text.
+		cmd.SilenceUsage = true
+		cmd.SilenceErrors = true
+		printError(os.Stderr, err)
+	}
+	return stmt, truth, err
+}
+
+// printError writes the given error to w in the same form as cobra does, followed by the diagnostic if it is a
+// *vera.ParseError, so that the one-shot commands and the REPL report errors the same way.
+func printError(w io.Writer, err error) {
+	_, _ = fmt.Fprintln(w, "Error:", err)
+	var pe *vera.ParseError
+	if errors.As(err, &pe) {
+		_, _ = fmt.Fprintln(w, pe.Diagnostic())
 	}
-	return vera.RenderTT(stmt, truth, os.Stdout, cs, !nocolor)
 }
@@ -0,0 +1,310 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"github.com/Ro5bert/vera"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var replCmd = &cobra.Command{
+	Use:   "repl",
+	Short: "Start an interactive session for exploring logical expressions",
+	Long: "Start an interactive session for exploring logical expressions, with line editing and history. Formulas " +
+		"can be named with 'let f = <expr>' and referred to by name in later expressions. Type :help for a list " +
+		"of commands.",
+	RunE: repl,
+	Args: cobra.NoArgs,
+}
+
+const replHelp = `let <name> = <expr>     name a formula, which later expressions can refer to
+<expr>                  print the expression with named formulas expanded
+:tt <expr>[, <expr>...] print a truth table for one or more expressions
+:sat <expr>             find a set of truth values which satisfies the expression
+:equiv <expr>, <expr>   check whether two expressions are equivalent (:equiv f g also works for names)
+:simplify <expr>        find a minimal sum-of-products form of the expression
+:defs                   list the named formulas
+:help                   print this help
+:quit                   exit (as does Ctrl-D)
+`
+
+// session is the state of a REPL session.
+type session struct {
+	out      io.Writer
+	opts     vera.Options
+	cs       *vera.CharSet
+	colorize bool
+	// defs maps the names of formulas to their (already expanded) definitions.
+	defs map[string]vera.Stmt
+	// line is the line of input being executed, into which parse errors point.
+	line string
+}
+
+// arg is a part of the line of input being executed, along with its byte offset in the line.
+type arg struct {
+	text   string
+	offset int
+}
+
+// trimArg returns the arg for the given text with surrounding whitespace removed.
+func trimArg(text string, offset int) arg {
+	trimmed := strings.TrimLeftFunc(text, unicode.IsSpace)
+	return arg{strings.TrimRightFunc(trimmed, unicode.IsSpace), offset + len(text) - len(trimmed)}
+}
+
+// newSession creates a session with no named formulas. Its output must be set before use.
+func newSession(opts vera.Options, cs *vera.CharSet, colorize bool) *session {
+	return &session{opts: opts, cs: cs, colorize: colorize, defs: make(map[string]vera.Stmt)}
+}
+
+func repl(cmd *cobra.Command, _ []string) error {
+	opts, err := parseOptions(cmd)
+	if err != nil {
+		return err
+	}
+	cs, colorize := tableStyle(cmd)
+	s := newSession(opts, cs, colorize)
+	var readLine func() (string, error)
+	// Without a terminal, errors go to stderr so that scripts can separate them from the results.
+	var errOut io.Writer = os.Stderr
+	if fd := int(os.Stdin.Fd()); term.IsTerminal(fd) {
+		state, err := term.MakeRaw(fd)
+		if err != nil {
+			return err
+		}
+		defer term.Restore(fd, state)
+		t := term.NewTerminal(struct {
+			io.Reader
+			io.Writer
+		}{os.Stdin, os.Stdout}, "vera> ")
+		readLine, s.out, errOut = t.ReadLine, t, t
+		_, _ = fmt.Fprintln(t, "Type :help for a list of commands.")
+	} else {
+		// Without a terminal (e.g. when input is piped in), read plain lines without a prompt.
+		scanner := bufio.NewScanner(os.Stdin)
+		readLine = func() (string, error) {
+			if scanner.Scan() {
+				return scanner.Text(), nil
+			}
+			if err := scanner.Err(); err != nil {
+				return "", err
+			}
+			return "", io.EOF
+		}
+		s.out = os.Stdout
+	}
+	for {
+		line, err := readLine()
+		if errors.Is(err, term.ErrPasteIndicator) {
+			// The line was pasted rather than typed, which makes no difference here.
+			err = nil
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		quit, err := s.exec(strings.TrimSpace(line))
+		if err != nil {
+			printError(errOut, err)
+		}
+		if quit {
+			return nil
+		}
+	}
+}
+
+// exec executes a line of input, returning whether the session should end.
+func (s *session) exec(line string) (bool, error) {
+	if line == "" {
+		return false, nil
+	}
+	s.line = line
+	if line == "let" || strings.HasPrefix(line, "let ") {
+		return false, s.let(arg{strings.TrimPrefix(line, "let"), len("let")})
+	}
+	if !strings.HasPrefix(line, ":") {
+		stmt, err := s.parse(arg{line, 0})
+		if err != nil {
+			return false, err
+		}
+		_, err = fmt.Fprintln(s.out, stmt)
+		return false, err
+	}
+	name, a := line, arg{"", len(line)}
+	if i := strings.IndexAny(line, " \t"); i >= 0 {
+		name, a = line[:i], trimArg(line[i:], i)
+	}
+	switch name {
+	case ":tt":
+		return false, s.tt(a)
+	case ":sat":
+		return false, s.sat(a)
+	case ":equiv":
+		return false, s.equiv(a)
+	case ":simplify":
+		return false, s.simplify(a)
+	case ":defs":
+		return false, s.listDefs()
+	case ":help":
+		_, err := fmt.Fprint(s.out, replHelp)
+		return false, err
+	case ":quit", ":q", ":exit":
+		return true, nil
+	default:
+		return false, fmt.Errorf("unknown command '%s'; type :help for a list of commands", name)
+	}
+}
+
+// parse parses an expression and replaces each atomic statement which names a formula with its definition.
+func (s *session) parse(a arg) (vera.Stmt, error) {
+	if a.text == "" {
+		return nil, errors.New("expected an expression")
+	}
+	stmt, _, err := vera.ParseWithOptions(a.text, s.opts)
+	var pe *vera.ParseError
+	if errors.As(err, &pe) {
+		// Point into the whole line rather than the part of it which was parsed, which has no newlines.
+		pe.Input, pe.Offset = s.line, pe.Offset+a.offset
+		pe.Column = utf8.RuneCountInString(s.line[:pe.Offset]) + 1
+	}
+	if err != nil {
+		return nil, err
+	}
+	return vera.Rewrite(stmt, func(stmt vera.Stmt) vera.Stmt {
+		if v, ok := stmt.(vera.Var); ok {
+			if def, ok := s.defs[string(v)]; ok {
+				return def
+			}
+		}
+		return stmt
+	}), nil
+}
+
+// parseList parses a list of expressions separated by commas.
+func (s *session) parseList(a arg) ([]vera.Stmt, error) {
+	var args []arg
+	offset := a.offset
+	for _, part := range strings.Split(a.text, ",") {
+		args = append(args, trimArg(part, offset))
+		offset += len(part) + len(",")
+	}
+	return s.parseAll(args)
+}
+
+// parseAll parses each of the given expressions.
+func (s *session) parseAll(args []arg) ([]vera.Stmt, error) {
+	stmts := make([]vera.Stmt, len(args))
+	for i, a := range args {
+		var err error
+		if stmts[i], err = s.parse(a); err != nil {
+			return nil, err
+		}
+	}
+	return stmts, nil
+}
+
+// let defines a formula given "<name> = <expr>".
+func (s *session) let(def arg) error {
+	i := strings.IndexByte(def.text, '=')
+	if i < 0 {
+		return errors.New("expected 'let <name> = <expr>'")
+	}
+	name := strings.TrimSpace(def.text[:i])
+	// The name must be one which can appear as an atomic statement in later expressions.
+	if v, _, err := vera.Parse(name); err != nil || v != vera.Var(name) {
+		return fmt.Errorf("invalid formula name '%s'", name)
+	}
+	stmt, err := s.parse(trimArg(def.text[i+1:], def.offset+i+1))
+	if err != nil {
+		return err
+	}
+	s.defs[name] = stmt
+	_, err = fmt.Fprintf(s.out, "%s = %s\n", name, stmt)
+	return err
+}
+
+func (s *session) tt(a arg) error {
+	stmts, err := s.parseList(a)
+	if err != nil {
+		return err
+	}
+	if len(stmts) == 1 {
+		return vera.RenderTT(stmts[0], vera.TruthFor(stmts[0]), s.out, s.cs, s.colorize)
+	}
+	return vera.RenderMultiTT(stmts, s.out, s.cs, s.colorize, vera.TTOptions{Diff: true})
+}
+
+func (s *session) sat(a arg) error {
+	stmt, err := s.parse(a)
+	if err != nil {
+		return err
+	}
+	truth, ok := vera.Satisfiable(stmt)
+	if !ok {
+		_, err = fmt.Fprintln(s.out, "UNSAT")
+		return err
+	}
+	_, err = fmt.Fprintf(s.out, "SAT\n%s\n", strings.Join(truthValues(truth), "\n"))
+	return err
+}
+
+func (s *session) equiv(a arg) error {
+	var stmts []vera.Stmt
+	var err error
+	if fields := strings.Fields(a.text); !strings.Contains(a.text, ",") && len(fields) == 2 {
+		i := strings.IndexFunc(a.text, unicode.IsSpace)
+		stmts, err = s.parseAll([]arg{{a.text[:i], a.offset}, trimArg(a.text[i:], a.offset+i)})
+	} else {
+		stmts, err = s.parseList(a)
+	}
+	if err != nil {
+		return err
+	}
+	if len(stmts) != 2 {
+		return errors.New("expected two expressions separated by a comma")
+	}
+	equivalent, counterexample := vera.Equivalent(stmts[0], stmts[1])
+	if equivalent {
+		_, err = fmt.Fprintln(s.out, "equivalent")
+		return err
+	}
+	if _, err = fmt.Fprintln(s.out, "not equivalent; counterexample:"); err != nil {
+		return err
+	}
+	return vera.RenderRows(stmts, []vera.Truth{counterexample}, s.out, s.cs, s.colorize)
+}
+
+func (s *session) simplify(a arg) error {
+	stmt, err := s.parse(a)
+	if err != nil {
+		return err
+	}
+	if len(vera.TruthFor(stmt).Names) >= 64 {
+		return errors.New("cannot simplify an expression with 64 or more atomics")
+	}
+	_, err = fmt.Fprintln(s.out, vera.Simplify(stmt))
+	return err
+}
+
+func (s *session) listDefs() error {
+	names := make([]string, 0, len(s.defs))
+	for name := range s.defs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, err := fmt.Fprintf(s.out, "%s = %s\n", name, s.defs[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bytes"
+	"github.com/Ro5bert/vera"
+	"testing"
+)
+
+func TestSessionExec(t *testing.T) {
+	type testCase struct {
+		// lines are executed in order in a new session; only the last may fail.
+		lines    []string
+		expected string
+		fails    bool
+	}
+	for _, c := range []testCase{
+		{[]string{"let f = a > b"}, "f = a > b\n", false},
+		{[]string{"let f = a > b", "let f = f & c"}, "f = a > b\nf = (a > b) & c\n", false},
+		{[]string{"let f = a > b", "f | d"}, "f = a > b\n(a > b) | d\n", false},
+		{[]string{"let f = a > b", "let g = !a | b", ":defs"}, "f = a > b\ng = !a | b\nf = a > b\ng = !a | b\n", false},
+		{[]string{"let and = a"}, "", true},
+		{[]string{"let 1x = a"}, "", true},
+		{[]string{"let f a = b"}, "", true},
+		{[]string{"let f"}, "", true},
+		{[]string{"let f = (a"}, "", true},
+		{[]string{"let f = a > b", ":equiv f, !a | b"}, "f = a > b\nequivalent\n", false},
+		{[]string{"let f = a > b", "let g = !a | b", ":equiv f g"}, "f = a > b\ng = !a | b\nequivalent\n", false},
+		{[]string{":equiv a, b"}, "not equivalent; counterexample:\n" +
+			"+----+-+-+\n" +
+			"|a  b|a|b|\n" +
+			"+----+-+-+\n" +
+			"|0  1|0|1|\n" +
+			"+----+-+-+\n", false},
+		{[]string{":equiv a"}, "", true},
+		{[]string{":equiv a, b, c"}, "", true},
+		{[]string{"let f = a & b", ":tt f"}, "f = a & b\n" +
+			"+----+-----+\n" +
+			"|a  b|a & b|\n" +
+			"+----+-----+\n" +
+			"|0  0|  0  |\n" +
+			"|0  1|  0  |\n" +
+			"|1  0|  0  |\n" +
+			"|1  1|  1  |\n" +
+			"+----+-----+\n", false},
+		{[]string{":tt a, !a"}, "+-+-+--+----+\n" +
+			"|a|a|!a|diff|\n" +
+			"+-+-+--+----+\n" +
+			"|0|0|1 | *  |\n" +
+			"|1|1|0 | *  |\n" +
+			"+-+-+--+----+\n", false},
+		{[]string{":sat a & !b"}, "SAT\na = 1\nb = 0\n", false},
+		{[]string{"let f = a & !a", ":sat f"}, "f = a & !a\nUNSAT\n", false},
+		{[]string{":sat"}, "", true},
+		{[]string{":simplify (a & b) | (a & !b)"}, "a\n", false},
+		{[]string{":foo"}, "", true},
+	} {
+		var buf bytes.Buffer
+		s := newSession(vera.Options{}, vera.ASCIIBoxCS, false)
+		s.out = &buf
+		var err error
+		for i, line := range c.lines {
+			if _, err = s.exec(line); err != nil && i < len(c.lines)-1 {
+				t.Fatalf("unexpected error: %v (input: %s)", err, line)
+			}
+		}
+		if c.fails != (err != nil) {
+			t.Fatalf("expected failure to be %t; got error %v (input: %q)", c.fails, err, c.lines)
+		}
+		if buf.String() != c.expected {
+			t.Fatalf("expected:\n%s\ngot:\n%s\n(input: %q)", c.expected, buf.String(), c.lines)
+		}
+	}
+}
+
+func TestSessionQuit(t *testing.T) {
+	s := newSession(vera.Options{}, vera.ASCIIBoxCS, false)
+	s.out = &bytes.Buffer{}
+	for _, line := range []string{":quit", ":q", ":exit"} {
+		if quit, err := s.exec(line); !quit || err != nil {
+			t.Fatalf("expected to quit; got %t, %v (input: %s)", quit, err, line)
+		}
+	}
+	if quit, _ := s.exec("a"); quit {
+		t.Fatalf("unexpected quit (input: a)")
+	}
+}
+
+func TestSessionErrorPosition(t *testing.T) {
+	type testCase struct {
+		line     string
+		expected string
+	}
+	for _, c := range []testCase{
+		{"a & & b", "Error: 1:5: unexpected '&'; expected '!', '(', '0', '1', or statement\na & & b\n    ^\n"},
+		{":sat  a & (b", "Error: 1:13: unexpected EOF; expected ')', '&', '|', '^', '>', '=', '↑', '↓', or '⊙'\n" +
+			":sat  a & (b\n            ^\n"},
+		{"let f = a b", "Error: 1:11: unexpected 'b'; expected '&', '|', '^', '>', '=', '↑', '↓', '⊙', or EOF\n" +
+			"let f = a b\n          ^\n"},
+		{":tt a, ¬¬b c", "Error: 1:12: unexpected 'c'; expected '&', '|', '^', '>', '=', '↑', '↓', '⊙', or EOF\n" +
+			":tt a, ¬¬b c\n           ^\n"},
+		{":equiv a (b", "Error: 1:12: unexpected EOF; expected ')', '&', '|', '^', '>', '=', '↑', '↓', or '⊙'\n" +
+			":equiv a (b\n           ^\n"},
+	} {
+		s := newSession(vera.Options{}, vera.ASCIIBoxCS, false)
+		s.out = &bytes.Buffer{}
+		_, err := s.exec(c.line)
+		if err == nil {
+			t.Fatalf("expected an error (input: %s)", c.line)
+		}
+		var buf bytes.Buffer
+		printError(&buf, err)
+		if buf.String() != c.expected {
+			t.Fatalf("expected:\n%s\ngot:\n%s", c.expected, buf.String())
+		}
+	}
+}
+
+func TestPrintError(t *testing.T) {
+	_, _, err := vera.Parse("a & & b")
+	var buf bytes.Buffer
+	printError(&buf, err)
+	expected := "Error: 1:5: unexpected '&'; expected '!', '(', '0', '1', or statement\na & & b\n    ^\n"
+	if buf.String() != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
@@ -0,0 +1,502 @@
+package vera
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// lit is a literal in a clause: a positive integer v denotes the variable v and -v denotes its negation, as in the
+// DIMACS CNF format. Variables are numbered from 1.
+type lit int
+
+// v returns the variable of the literal.
+func (l lit) v() int {
+	if l < 0 {
+		return int(-l)
+	}
+	return int(l)
+}
+
+// clause is a disjunction of literals.
+type clause []lit
+
+// cnf is a conjunction of clauses over numbered variables. Variables which correspond to atomic statements are recorded
+// in names; any other variables are auxiliary variables introduced by the Tseitin transformation.
+type cnf struct {
+	clauses []clause
+	nVars   int
+	// names maps each variable which corresponds to an atomic statement to the name of that statement.
+	names map[int]string
+	// vars is the inverse of names.
+	vars map[string]int
+	// trueVar is the variable which is constrained to be true and is used to encode constants, or 0 if it has not been
+	// needed yet.
+	trueVar int
+}
+
+func newCNF() *cnf {
+	return &cnf{names: make(map[int]string), vars: make(map[string]int)}
+}
+
+// newVar allocates a new variable and returns it.
+func (c *cnf) newVar() int {
+	c.nVars++
+	return c.nVars
+}
+
+// atomVar returns the variable for the atomic statement with the given name, allocating it if necessary.
+func (c *cnf) atomVar(name string) int {
+	if v, ok := c.vars[name]; ok {
+		return v
+	}
+	v := c.newVar()
+	c.vars[name] = v
+	c.names[v] = name
+	return v
+}
+
+// add adds a clause consisting of the given literals.
+func (c *cnf) add(lits ...lit) {
+	c.clauses = append(c.clauses, clause(lits))
+}
+
+// renumber returns the clauses of the CNF with trueVar substituted away (dropping satisfied clauses and false literals),
+// duplicate literals removed, and tautologies dropped. The variables are renumbered from 1: the atomic statements with
+// the given names come first, in the given order, followed by the auxiliary variables in order of appearance. The
+// number of variables is also returned.
+func (c *cnf) renumber(atomics []string) ([]clause, int) {
+	num := make(map[int]int, c.nVars)
+	for i, name := range atomics {
+		if v, ok := c.vars[name]; ok {
+			num[v] = i + 1
+		}
+	}
+	nVars := len(atomics)
+	var clauses []clause
+outer:
+	for _, cl := range c.clauses {
+		var out clause
+		for _, l := range cl {
+			v := l.v()
+			if v == c.trueVar {
+				// trueVar is always true, so substitute its value.
+				if l > 0 {
+					continue outer
+				}
+				continue
+			}
+			n, ok := num[v]
+			if !ok {
+				nVars++
+				n = nVars
+				num[v] = n
+			}
+			nl := lit(n)
+			if l < 0 {
+				nl = -nl
+			}
+			if containsLit(out, -nl) {
+				// The clause is a tautology.
+				continue outer
+			}
+			if !containsLit(out, nl) {
+				out = append(out, nl)
+			}
+		}
+		clauses = append(clauses, out)
+	}
+	return clauses, nVars
+}
+
+// containsLit reports whether the clause contains the given literal.
+func containsLit(cl clause, l lit) bool {
+	for _, x := range cl {
+		if x == l {
+			return true
+		}
+	}
+	return false
+}
+
+// tseitin returns an equisatisfiable CNF for the given Stmt: the CNF is satisfiable exactly when the Stmt is, and every
+// model of the CNF restricted to the atomic statements is a model of the Stmt. Since each auxiliary variable is defined
+// to be equivalent to the subexpression it replaces, every model of the Stmt extends to exactly one model of the CNF.
+func tseitin(s Stmt) *cnf {
+	c := newCNF()
+	c.assert(s, true)
+	return c
+}
+
+// assert adds clauses requiring the given Stmt to have the given value. Conjunctions are split into separate assertions
+// and disjunctions become a single clause, so auxiliary variables are only introduced for nested subexpressions.
+func (c *cnf) assert(s Stmt, val bool) {
+	switch s := s.(type) {
+	case Const:
+		if bool(s) != val {
+			// An empty clause can never be satisfied.
+			c.add()
+		}
+		return
+	case Not:
+		c.assert(s.X, !val)
+		return
+	case Binary:
+		switch {
+		case s.Op == OpAnd && val, s.Op == OpNand && !val:
+			c.assert(s.Left, true)
+			c.assert(s.Right, true)
+			return
+		case s.Op == OpOr && !val, s.Op == OpNor && val:
+			c.assert(s.Left, false)
+			c.assert(s.Right, false)
+			return
+		case s.Op == OpCond && !val:
+			c.assert(s.Left, true)
+			c.assert(s.Right, false)
+			return
+		}
+	}
+	c.add(c.disjuncts(s, val, nil)...)
+}
+
+// disjuncts appends to lits the literals of a clause requiring the given Stmt to have the given value, and returns the
+// extended slice. Disjunctions are flattened into the clause; anything else is encoded as a single literal.
+func (c *cnf) disjuncts(s Stmt, val bool, lits []lit) []lit {
+	switch s := s.(type) {
+	case Not:
+		return c.disjuncts(s.X, !val, lits)
+	case Binary:
+		switch {
+		case s.Op == OpOr && val, s.Op == OpNor && !val:
+			return c.disjuncts(s.Right, true, c.disjuncts(s.Left, true, lits))
+		case s.Op == OpAnd && !val, s.Op == OpNand && val:
+			return c.disjuncts(s.Right, false, c.disjuncts(s.Left, false, lits))
+		case s.Op == OpCond && val:
+			return c.disjuncts(s.Right, true, c.disjuncts(s.Left, false, lits))
+		}
+	}
+	l := c.encode(s)
+	if !val {
+		l = -l
+	}
+	return append(lits, l)
+}
+
+// encode adds clauses defining a literal which is equivalent to the given Stmt, and returns that literal.
+func (c *cnf) encode(s Stmt) lit {
+	switch s := s.(type) {
+	case Const:
+		if c.trueVar == 0 {
+			c.trueVar = c.newVar()
+			c.add(lit(c.trueVar))
+		}
+		if s {
+			return lit(c.trueVar)
+		}
+		return -lit(c.trueVar)
+	case Var:
+		return lit(c.atomVar(string(s)))
+	case Not:
+		return -c.encode(s.X)
+	case Binary:
+		a, b := c.encode(s.Left), c.encode(s.Right)
+		g := lit(c.newVar())
+		switch s.Op {
+		case OpAnd, OpNand:
+			c.add(-g, a)
+			c.add(-g, b)
+			c.add(g, -a, -b)
+		case OpOr, OpNor:
+			c.add(g, -a)
+			c.add(g, -b)
+			c.add(-g, a, b)
+		case OpCond:
+			// a > b is equivalent to !a | b.
+			c.add(g, a)
+			c.add(g, -b)
+			c.add(-g, -a, b)
+		case OpXor, OpBicond, OpXnor:
+			c.add(-g, a, b)
+			c.add(-g, -a, -b)
+			c.add(g, -a, b)
+			c.add(g, a, -b)
+		default:
+			panic(fmt.Sprintf("invalid Op %d", s.Op))
+		}
+		// NAND, NOR, and XNOR/bicond are the negations of AND, OR, and XOR respectively.
+		switch s.Op {
+		case OpNand, OpNor, OpBicond, OpXnor:
+			return -g
+		}
+		return g
+	case IfThenElse:
+		cond, then, els := c.encode(s.Cond), c.encode(s.Then), c.encode(s.Else)
+		g := lit(c.newVar())
+		c.add(-g, -cond, then)
+		c.add(-g, cond, els)
+		c.add(g, -cond, -then)
+		c.add(g, cond, -els)
+		// Redundant, but helps propagation when the branches agree.
+		c.add(-g, then, els)
+		c.add(g, -then, -els)
+		return g
+	default:
+		panic(fmt.Sprintf("unexpected Stmt type %T", s))
+	}
+}
+
+// literal is an atomic statement or its negation, identified by name. Unlike lit, it is not tied to a numbering of
+// variables, so it is used for normal forms which are converted back into Stmts.
+type literal struct {
+	name string
+	neg  bool
+}
+
+func (l literal) less(o literal) bool {
+	return l.name < o.name || (l.name == o.name && !l.neg && o.neg)
+}
+
+func (l literal) stmt() Stmt {
+	if l.neg {
+		return Not{Var(l.name)}
+	}
+	return Var(l.name)
+}
+
+// term is a sorted set of literals, which is interpreted as a clause (disjunction) in CNF or a cube (conjunction) in
+// DNF.
+type term []literal
+
+// merge returns the union of two terms. The boolean return value is false if the union contains both an atomic
+// statement and its negation (i.e. it would be a tautological clause or a contradictory cube).
+func (t term) merge(o term) (term, bool) {
+	merged := make(term, 0, len(t)+len(o))
+	i, j := 0, 0
+	for i < len(t) || j < len(o) {
+		var next literal
+		switch {
+		case j == len(o) || (i < len(t) && t[i].less(o[j])):
+			next = t[i]
+			i++
+		default:
+			next = o[j]
+			j++
+		}
+		if n := len(merged); n > 0 && merged[n-1].name == next.name {
+			if merged[n-1].neg != next.neg {
+				return nil, false
+			}
+			continue
+		}
+		merged = append(merged, next)
+	}
+	return merged, true
+}
+
+// subsumes reports whether every literal in t is also in o.
+func (t term) subsumes(o term) bool {
+	j := 0
+	for _, l := range t {
+		for j < len(o) && o[j].less(l) {
+			j++
+		}
+		if j == len(o) || o[j] != l {
+			return false
+		}
+		j++
+	}
+	return true
+}
+
+// simplifyTerms removes duplicate and subsumed terms (e.g. the clause "a | b" is redundant next to the clause "a").
+func simplifyTerms(terms []term) []term {
+	simplified := make([]term, 0, len(terms))
+outer:
+	for i, t := range terms {
+		for j, o := range terms {
+			// Of two identical terms, keep only the first.
+			if i != j && o.subsumes(t) && (len(o) < len(t) || j < i) {
+				continue outer
+			}
+		}
+		simplified = append(simplified, t)
+	}
+	return simplified
+}
+
+// product returns the terms formed by merging each term in a with each term in b, which distributes one normal form
+// over another (e.g. in CNF, (a1 & a2) | (b1 & b2) becomes (a1 | b1) & (a1 | b2) & (a2 | b1) & (a2 | b2)).
+func product(a []term, b []term) []term {
+	terms := make([]term, 0, len(a)*len(b))
+	for _, ta := range a {
+		for _, tb := range b {
+			if t, ok := ta.merge(tb); ok {
+				terms = append(terms, t)
+			}
+		}
+	}
+	return simplifyTerms(terms)
+}
+
+// union returns the terms in both a and b.
+func union(a []term, b []term) []term {
+	terms := make([]term, 0, len(a)+len(b))
+	return simplifyTerms(append(append(terms, a...), b...))
+}
+
+// cnfTerms returns the clauses of a CNF which is equivalent to the given Stmt if val is true, or its negation if val is
+// false. An empty slice of clauses is true and an empty clause is false.
+func cnfTerms(s Stmt, val bool) []term {
+	switch s := s.(type) {
+	case Const:
+		if bool(s) == val {
+			return []term{}
+		}
+		return []term{{}}
+	case Var:
+		return []term{{literal{string(s), !val}}}
+	case Not:
+		return cnfTerms(s.X, !val)
+	case Binary:
+		l, r := s.Left, s.Right
+		switch s.Op {
+		case OpNand, OpNor, OpBicond, OpXnor:
+			// These are the negations of AND, OR, and XOR respectively; see below.
+			val = !val
+		}
+		switch s.Op {
+		case OpAnd, OpNand:
+			if val {
+				return union(cnfTerms(l, true), cnfTerms(r, true))
+			}
+			return product(cnfTerms(l, false), cnfTerms(r, false))
+		case OpOr, OpNor:
+			if val {
+				return product(cnfTerms(l, true), cnfTerms(r, true))
+			}
+			return union(cnfTerms(l, false), cnfTerms(r, false))
+		case OpCond:
+			if val {
+				return product(cnfTerms(l, false), cnfTerms(r, true))
+			}
+			return union(cnfTerms(l, true), cnfTerms(r, false))
+		case OpXor, OpBicond, OpXnor:
+			// a ^ b is (a | b) & (!a | !b), and !(a ^ b) is (!a | b) & (a | !b).
+			return union(product(cnfTerms(l, val), cnfTerms(r, true)), product(cnfTerms(l, !val), cnfTerms(r, false)))
+		default:
+			panic(fmt.Sprintf("invalid Op %d", s.Op))
+		}
+	case IfThenElse:
+		// if c then t else e is (!c | t) & (c | e).
+		return union(product(cnfTerms(s.Cond, false), cnfTerms(s.Then, val)),
+			product(cnfTerms(s.Cond, true), cnfTerms(s.Else, val)))
+	default:
+		panic(fmt.Sprintf("unexpected Stmt type %T", s))
+	}
+}
+
+// dnfTerms returns the cubes of a DNF which is equivalent to the given Stmt. An empty slice of cubes is false and an
+// empty cube is true.
+func dnfTerms(s Stmt) []term {
+	// By De Morgan's laws, negating each literal in a CNF of !s gives a DNF of s.
+	terms := cnfTerms(s, false)
+	for _, t := range terms {
+		for i := range t {
+			t[i].neg = !t[i].neg
+		}
+	}
+	return terms
+}
+
+// termsToStmt builds a Stmt from the given terms, joining the literals in each term with inner and the terms with
+// outer. empty is the value of a term with no literals, which is also the value of the whole Stmt if such a term is
+// present (e.g. an empty clause makes a CNF false).
+func termsToStmt(terms []term, outer Op, inner Op, empty bool) Stmt {
+	if len(terms) == 0 {
+		return Const(!empty)
+	}
+	for _, t := range terms {
+		if len(t) == 0 {
+			return Const(empty)
+		}
+	}
+	var s Stmt
+	for _, t := range terms {
+		var ts Stmt
+		for i, l := range t {
+			if i == 0 {
+				ts = l.stmt()
+			} else {
+				ts = Binary{inner, ts, l.stmt()}
+			}
+		}
+		if s == nil {
+			s = ts
+		} else {
+			s = Binary{outer, s, ts}
+		}
+	}
+	return s
+}
+
+// ToCNF returns a Stmt in conjunctive normal form (a conjunction of disjunctions of possibly negated atomic statements)
+// which is equivalent to the given Stmt. Duplicate and subsumed clauses are removed. The result may be exponentially
+// larger than the given Stmt; see ToTseitinCNF for a conversion which avoids this.
+func ToCNF(s Stmt) Stmt {
+	return termsToStmt(cnfTerms(s, true), OpAnd, OpOr, false)
+}
+
+// ToDNF returns a Stmt in disjunctive normal form (a disjunction of conjunctions of possibly negated atomic statements)
+// which is equivalent to the given Stmt. Duplicate and subsumed cubes are removed. The result may be exponentially
+// larger than the given Stmt.
+func ToDNF(s Stmt) Stmt {
+	return termsToStmt(dnfTerms(s), OpOr, OpAnd, true)
+}
+
+// ToTseitinCNF returns a Stmt in conjunctive normal form which is equisatisfiable with the given Stmt, using the Tseitin
+// transformation: each nested subexpression is replaced by a new atomic statement constrained to be equivalent to it,
+// so the result is only linearly larger than the given Stmt. The new atomic statements are named "_t1", "_t2", etc.
+// (with additional leading underscores if necessary to avoid clashing with the atomic statements in the given Stmt).
+// Every model of the result is a model of the given Stmt when restricted to its atomic statements, and every model of
+// the given Stmt extends to exactly one model of the result.
+func ToTseitinCNF(s Stmt) Stmt {
+	c := tseitin(s)
+	auxPrefix := "_t"
+	for clash := true; clash; {
+		clash = false
+		for name := range c.vars {
+			if strings.HasPrefix(name, auxPrefix) {
+				auxPrefix = "_" + auxPrefix
+				clash = true
+				break
+			}
+		}
+	}
+	atomics := make([]string, 0, len(c.vars))
+	for name := range c.vars {
+		atomics = append(atomics, name)
+	}
+	sort.Strings(atomics)
+	clauses, _ := c.renumber(atomics)
+	// The auxiliary variables are numbered consecutively after the atomic statements, in order of appearance.
+	terms := make([]term, len(clauses))
+	for i, cl := range clauses {
+		for _, l := range cl {
+			n := l.v()
+			name := fmt.Sprintf("%s%d", auxPrefix, n-len(atomics))
+			if n <= len(atomics) {
+				name = atomics[n-1]
+			}
+			terms[i] = append(terms[i], literal{name, l < 0})
+		}
+		sortTerm(terms[i])
+	}
+	return termsToStmt(terms, OpAnd, OpOr, false)
+}
+
+// sortTerm sorts the literals in the given term in place and returns it.
+func sortTerm(t term) term {
+	sort.Slice(t, func(i, j int) bool { return t[i].less(t[j]) })
+	return t
+}
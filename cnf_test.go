@@ -0,0 +1,129 @@
+package vera
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// isNormalForm reports whether the given Stmt is a conjunction (if outer is OpAnd) or disjunction (if outer is OpOr) of
+// terms, each of which joins possibly negated atomic statements with inner.
+func isNormalForm(s Stmt, outer Op, inner Op) bool {
+	isLiteral := func(s Stmt) bool {
+		if n, ok := s.(Not); ok {
+			s = n.X
+		}
+		_, ok := s.(Var)
+		return ok
+	}
+	var isTerm func(Stmt) bool
+	isTerm = func(s Stmt) bool {
+		if b, ok := s.(Binary); ok && b.Op == inner {
+			return isTerm(b.Left) && isTerm(b.Right)
+		}
+		return isLiteral(s)
+	}
+	var isForm func(Stmt) bool
+	isForm = func(s Stmt) bool {
+		if b, ok := s.(Binary); ok && b.Op == outer {
+			return isForm(b.Left) && isForm(b.Right)
+		}
+		return isTerm(s)
+	}
+	if _, ok := s.(Const); ok {
+		return true
+	}
+	return isForm(s)
+}
+
+func TestToCNFAndDNF(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	for i := 0; i < 500; i++ {
+		s := randomStmt(r, 4)
+		cnf := ToCNF(s)
+		if !isNormalForm(cnf, OpAnd, OpOr) {
+			t.Fatalf("%s is not in CNF (from %s)", cnf, s)
+		}
+		if equiv, truth := Equivalent(s, cnf); !equiv {
+			t.Fatalf("%s is not equivalent to %s at %s", cnf, s, truth)
+		}
+		dnf := ToDNF(s)
+		if !isNormalForm(dnf, OpOr, OpAnd) {
+			t.Fatalf("%s is not in DNF (from %s)", dnf, s)
+		}
+		if equiv, truth := Equivalent(s, dnf); !equiv {
+			t.Fatalf("%s is not equivalent to %s at %s", dnf, s, truth)
+		}
+	}
+}
+
+func TestToCNFString(t *testing.T) {
+	type testCase struct {
+		input string
+		cnf   string
+		dnf   string
+	}
+	for _, c := range []testCase{
+		{"a > b", "!a | b", "!a | b"},
+		{"a = b", "(!a | b) & (a | !b)", "(!a & !b) | (a & b)"},
+		{"(a & b) | c", "(a | c) & (b | c)", "(a & b) | c"},
+		{"a & (a | b)", "a", "a"},
+		{"a | !a", "1", "a | !a"},
+		{"a & !a", "a & !a", "0"},
+	} {
+		stmt, _, err := Parse(c.input)
+		if err != nil {
+			t.Fatalf("error occurred while parsing: %v (input: %s)", err, c.input)
+		}
+		if got := ToCNF(stmt).String(); got != c.cnf {
+			t.Fatalf("expected CNF %s; got %s (input: %s)", c.cnf, got, c.input)
+		}
+		if got := ToDNF(stmt).String(); got != c.dnf {
+			t.Fatalf("expected DNF %s; got %s (input: %s)", c.dnf, got, c.input)
+		}
+	}
+}
+
+func TestToTseitinCNF(t *testing.T) {
+	r := rand.New(rand.NewSource(3))
+	for i := 0; i < 500; i++ {
+		s := randomStmt(r, 5)
+		ts := ToTseitinCNF(s)
+		if !isNormalForm(ts, OpAnd, OpOr) {
+			t.Fatalf("%s is not in CNF (from %s)", ts, s)
+		}
+		model, sat := Satisfiable(ts)
+		if sat != bruteSatisfiable(s) {
+			t.Fatalf("%s is not equisatisfiable with %s", ts, s)
+		}
+		if !sat {
+			continue
+		}
+		// The model of the Tseitin CNF, restricted to the original atomics, must satisfy the original Stmt.
+		truth := TruthFor(s)
+		for _, name := range truth.Names {
+			v, _ := model.Get(name)
+			truth.Set(name, v)
+		}
+		if !s.Eval(truth) {
+			t.Fatalf("model %s of %s does not satisfy %s", model, ts, s)
+		}
+	}
+}
+
+func TestToTseitinCNFNames(t *testing.T) {
+	stmt, _, err := Parse("(_t1 & b) | (c & d)")
+	if err != nil {
+		t.Fatalf("error occurred while parsing: %v", err)
+	}
+	ts := ToTseitinCNF(stmt)
+	for name := range atomicsOf(ts) {
+		switch name {
+		case "_t1", "b", "c", "d":
+		default:
+			if !strings.HasPrefix(name, "__t") {
+				t.Fatalf("auxiliary atomic %s in %s may clash with the original atomics", name, ts)
+			}
+		}
+	}
+}
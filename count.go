@@ -0,0 +1,247 @@
+package vera
+
+import (
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// CountModels returns the number of sets of truth values for the atomic statements in the given Stmt at which it is
+// true, i.e. the number of rows in its truth table with a 1 in the output column. Rather than evaluating every row, the
+// Stmt is converted to CNF via the Tseitin transformation (which preserves the number of models) and counted with a
+// DPLL-style search which splits the clauses into independent components and caches the count of each, so it is
+// practical for many Stmts with too many atomic statements for a truth table.
+func CountModels(s Stmt) *big.Int {
+	c, _ := tseitinAll(s)
+	// Each auxiliary variable appears in the clauses defining it, so the variables in scope are the atomic statements
+	// (even those which appear in no clause) and the variables which appear in the clauses.
+	vars := make(map[int]bool, c.nVars)
+	for _, v := range c.vars {
+		vars[v] = true
+	}
+	for _, cl := range c.clauses {
+		for _, l := range cl {
+			vars[l.v()] = true
+		}
+	}
+	mc := &modelCounter{cache: make(map[string]*big.Int)}
+	return mc.count(c.clauses, len(vars))
+}
+
+// Models returns an iterator over the sets of truth values at which the given Stmt is true. Each call to the iterator
+// returns the next set of truth values and true, or a Truth with every truth value set to false and false once every
+// set has been returned. The sets are returned in no particular order, without repetition.
+// The sets are found lazily with the same SAT solver as Satisfiable: after each one is found, a clause excluding it is
+// added to the solver before it searches for the next, so a few models of a Stmt with many atomic statements can be
+// found without enumerating its truth table.
+func Models(s Stmt) func() (Truth, bool) {
+	c, truth := tseitinAll(s)
+	sv := newSolver(c)
+	return func() (Truth, bool) {
+		if !sv.solve() {
+			return truth, false
+		}
+		model := truth.Clone()
+		sv.model(c, &model)
+		// Block this model by requiring at least one atomic statement to have a different value.
+		block := make(clause, 0, len(c.vars))
+		for _, v := range c.vars {
+			if sv.assigns[v] == lTrue {
+				block = append(block, -lit(v))
+			} else {
+				block = append(block, lit(v))
+			}
+		}
+		sv.addClause(block)
+		return model, true
+	}
+}
+
+// tseitinAll is like tseitin, but allocates a variable for every atomic statement in the given Stmt first, so that the
+// variables of the CNF include every atomic statement even if the transformation would not otherwise need it. It also
+// returns the Truth for the Stmt (see TruthFor).
+func tseitinAll(s Stmt) (*cnf, Truth) {
+	truth := TruthFor(s)
+	c := newCNF()
+	for _, name := range truth.Names {
+		c.atomVar(name)
+	}
+	c.assert(s, true)
+	return c, truth
+}
+
+// modelCounter counts the models of CNFs, caching the count of each component it encounters.
+type modelCounter struct {
+	// cache maps the canonical form of a component (see componentKey) to its number of models.
+	cache map[string]*big.Int
+}
+
+// count returns the number of assignments to n variables satisfying the given clauses, whose variables must be among
+// the n.
+func (mc *modelCounter) count(clauses []clause, n int) *big.Int {
+	clauses, n, ok := propagateUnits(clauses, n)
+	if !ok {
+		return new(big.Int)
+	}
+	result := big.NewInt(1)
+	for _, comp := range components(clauses) {
+		nComp := len(clauseVars(comp))
+		n -= nComp
+		result.Mul(result, mc.countComponent(comp, nComp))
+		if result.Sign() == 0 {
+			return result
+		}
+	}
+	// The variables which do not appear in any clause can have either value.
+	return result.Lsh(result, uint(n))
+}
+
+// countComponent returns the number of assignments to the n variables of the given connected clauses satisfying them,
+// by branching on the variable which appears most often.
+func (mc *modelCounter) countComponent(clauses []clause, n int) *big.Int {
+	key := componentKey(clauses)
+	if cached, ok := mc.cache[key]; ok {
+		return cached
+	}
+	occurrences := make(map[int]int)
+	best := 0
+	for _, cl := range clauses {
+		for _, l := range cl {
+			v := l.v()
+			occurrences[v]++
+			if occurrences[v] > occurrences[best] || (occurrences[v] == occurrences[best] && v < best) {
+				best = v
+			}
+		}
+	}
+	result := new(big.Int)
+	for _, l := range []lit{lit(best), -lit(best)} {
+		if conditioned, ok := condition(clauses, l); ok {
+			result.Add(result, mc.count(conditioned, n-1))
+		}
+	}
+	mc.cache[key] = result
+	return result
+}
+
+// condition returns the given clauses with the given literal assigned true: clauses containing it are removed, and its
+// negation is removed from the remaining clauses. The boolean return value is false if a clause becomes empty.
+func condition(clauses []clause, l lit) ([]clause, bool) {
+	result := make([]clause, 0, len(clauses))
+outer:
+	for _, cl := range clauses {
+		var reduced clause
+		for i, m := range cl {
+			if m == l {
+				continue outer
+			}
+			if m == -l && reduced == nil {
+				// Copy the literals before this one the first time the clause needs to change.
+				reduced = append(make(clause, 0, len(cl)-1), cl[:i]...)
+			} else if reduced != nil && m != -l {
+				reduced = append(reduced, m)
+			}
+		}
+		if reduced == nil {
+			result = append(result, cl)
+			continue
+		}
+		if len(reduced) == 0 {
+			return nil, false
+		}
+		result = append(result, reduced)
+	}
+	return result, true
+}
+
+// propagateUnits repeatedly assigns the literal in a unit clause (a clause with only one literal) to true, returning
+// the resulting clauses and number of variables. The boolean return value is false if this leads to a conflict (or
+// there is an empty clause to begin with).
+func propagateUnits(clauses []clause, n int) ([]clause, int, bool) {
+	for {
+		unit := lit(0)
+		for _, cl := range clauses {
+			if len(cl) == 0 {
+				return nil, n, false
+			}
+			if len(cl) == 1 {
+				unit = cl[0]
+				break
+			}
+		}
+		if unit == 0 {
+			return clauses, n, true
+		}
+		var ok bool
+		if clauses, ok = condition(clauses, unit); !ok {
+			return nil, n, false
+		}
+		n--
+	}
+}
+
+// components partitions the given clauses into connected components, where two clauses are connected if they share a
+// variable.
+func components(clauses []clause) [][]clause {
+	parent := make(map[int]int)
+	var find func(int) int
+	find = func(v int) int {
+		p, ok := parent[v]
+		if !ok || p == v {
+			return v
+		}
+		root := find(p)
+		parent[v] = root
+		return root
+	}
+	for _, cl := range clauses {
+		root := find(cl[0].v())
+		for _, l := range cl[1:] {
+			if r := find(l.v()); r != root {
+				parent[r] = root
+			}
+		}
+	}
+	index := make(map[int]int)
+	var comps [][]clause
+	for _, cl := range clauses {
+		root := find(cl[0].v())
+		i, ok := index[root]
+		if !ok {
+			i = len(comps)
+			index[root] = i
+			comps = append(comps, nil)
+		}
+		comps[i] = append(comps[i], cl)
+	}
+	return comps
+}
+
+// clauseVars returns the set of variables appearing in the given clauses.
+func clauseVars(clauses []clause) map[int]bool {
+	vars := make(map[int]bool)
+	for _, cl := range clauses {
+		for _, l := range cl {
+			vars[l.v()] = true
+		}
+	}
+	return vars
+}
+
+// componentKey returns a canonical string for the given clauses, which is the same for any ordering of the clauses or
+// of the literals within them.
+func componentKey(clauses []clause) string {
+	strs := make([]string, len(clauses))
+	for i, cl := range clauses {
+		sorted := append(clause(nil), cl...)
+		sort.Slice(sorted, func(a, b int) bool { return sorted[a] < sorted[b] })
+		parts := make([]string, len(sorted))
+		for j, l := range sorted {
+			parts[j] = strconv.Itoa(int(l))
+		}
+		strs[i] = strings.Join(parts, " ")
+	}
+	sort.Strings(strs)
+	return strings.Join(strs, ",")
+}
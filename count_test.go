@@ -0,0 +1,78 @@
+package vera
+
+import (
+	"fmt"
+	"math/big"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// bruteCountModels counts models by evaluating the Stmt at every set of truth values.
+func bruteCountModels(s Stmt) int64 {
+	truth := TruthFor(s)
+	count := int64(0)
+	for truth.Val = 0; truth.Val < 1<<len(truth.Names); truth.Val++ {
+		if s.Eval(truth) {
+			count++
+		}
+	}
+	return count
+}
+
+func TestCountModelsRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 2000; i++ {
+		s := randomStmt(r, 5)
+		expected := bruteCountModels(s)
+		if actual := CountModels(s); actual.Cmp(big.NewInt(expected)) != 0 {
+			t.Fatalf("expected %d models for %s; got %s", expected, s, actual)
+		}
+	}
+}
+
+func TestCountModelsWide(t *testing.T) {
+	// a0 ^ a1 ^ ... ^ a99 is true for exactly half of the 2^100 sets of truth values.
+	var sb strings.Builder
+	for i := 0; i < 100; i++ {
+		if i > 0 {
+			sb.WriteString(" ^ ")
+		}
+		_, _ = fmt.Fprintf(&sb, "a%d", i)
+	}
+	s, _, err := ParseWithOptions(sb.String(), Options{Precedence: Standard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := new(big.Int).Lsh(big.NewInt(1), 99)
+	if actual := CountModels(s); actual.Cmp(expected) != 0 {
+		t.Fatalf("expected %s models; got %s", expected, actual)
+	}
+	if actual := CountModels(pigeonhole(6)); actual.Sign() != 0 {
+		t.Fatalf("expected no models for the pigeonhole principle; got %s", actual)
+	}
+}
+
+func TestModels(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 500; i++ {
+		s := randomStmt(r, 5)
+		seen := make(map[uint64]bool)
+		next := Models(s)
+		for truth, ok := next(); ok; truth, ok = next() {
+			if !s.Eval(truth) {
+				t.Fatalf("model %s does not satisfy %s", truth, s)
+			}
+			if seen[truth.Val] {
+				t.Fatalf("model %s of %s was returned twice", truth, s)
+			}
+			seen[truth.Val] = true
+		}
+		if int64(len(seen)) != bruteCountModels(s) {
+			t.Fatalf("expected %d models for %s; got %d", bruteCountModels(s), s, len(seen))
+		}
+		if _, ok := next(); ok {
+			t.Fatalf("expected the iterator for %s to stay exhausted", s)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package vera
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// dimacsNameComment is the format of the comments which WriteDIMACS uses to record the name of the atomic statement
+// corresponding to each variable, and which ReadDIMACS uses to restore them.
+const dimacsNameComment = "c var %d %s\n"
+
+// WriteDIMACS writes a CNF which is equisatisfiable with the given Stmt to the given io.Writer in the DIMACS CNF format
+// used by SAT solvers. The CNF is produced by the Tseitin transformation (see ToTseitinCNF), so a Stmt which is already
+// in CNF is written as is, and any other Stmt gains auxiliary variables; in either case, each model of the Stmt
+// corresponds to exactly one model of the CNF. The atomic statements are numbered first, in lexicographic order, and a
+// comment of the form "c var <n> <name>" records the name of each.
+func WriteDIMACS(s Stmt, w io.Writer) error {
+	// TruthFor sorts the names in descending order.
+	names := TruthFor(s).Names
+	atomics := make([]string, len(names))
+	for i, name := range names {
+		atomics[len(names)-1-i] = name
+	}
+	clauses, nVars := tseitin(s).renumber(atomics)
+
+	bw := bufio.NewWriter(w)
+	for i, name := range atomics {
+		_, _ = fmt.Fprintf(bw, dimacsNameComment, i+1, name)
+	}
+	_, _ = fmt.Fprintf(bw, "p cnf %d %d\n", nVars, len(clauses))
+	for _, cl := range clauses {
+		for _, n := range cl {
+			_, _ = fmt.Fprintf(bw, "%d ", n)
+		}
+		_, _ = fmt.Fprintln(bw, "0")
+	}
+	// Errors are sticky in a bufio.Writer, so any error from the writes above is returned here.
+	return bw.Flush()
+}
+
+// containsInt reports whether the given slice contains the given int.
+func containsInt(s []int, n int) bool {
+	for _, m := range s {
+		if m == n {
+			return true
+		}
+	}
+	return false
+}
+
+// ReadDIMACS reads a CNF in the DIMACS CNF format from the given io.Reader and returns it as a Stmt: a conjunction of
+// disjunctions of possibly negated atomic statements, in the same order as in the input. Variables are named as
+// recorded by comments of the form "c var <n> <name>" (as written by WriteDIMACS), or "x<n>" otherwise (with
+// additional leading underscores if necessary to avoid clashing with the recorded names). Variables which do not appear
+// in any clause do not appear in the Stmt. A CNF with no clauses is Const(true), and one with an empty clause is
+// Const(false).
+func ReadDIMACS(r io.Reader) (Stmt, error) {
+	scanner := bufio.NewScanner(r)
+	names := make(map[int]string)
+	vars := make(map[string]int)
+	nVars, nClauses := -1, 0
+	var clauses [][]int
+	var cl []int
+	line := 0
+scan:
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		switch {
+		case text == "":
+			continue
+		case text[0] == 'c':
+			var n int
+			var name string
+			if _, err := fmt.Sscanf(text, "c var %d %s", &n, &name); err == nil {
+				if !isValidName(name) {
+					return nil, fmt.Errorf("dimacs: line %d: invalid name '%s'", line, name)
+				}
+				if v, ok := vars[name]; ok && v != n {
+					return nil, fmt.Errorf("dimacs: line %d: name '%s' is used for variables %d and %d", line, name, v,
+						n)
+				}
+				if old, ok := names[n]; ok && old != name {
+					return nil, fmt.Errorf("dimacs: line %d: variable %d is named both '%s' and '%s'", line, n, old,
+						name)
+				}
+				names[n] = name
+				vars[name] = n
+			}
+			continue
+		case text[0] == '%':
+			// Some benchmark sets end with a line containing only '%'.
+			break scan
+		case text[0] == 'p':
+			if nVars >= 0 {
+				return nil, fmt.Errorf("dimacs: line %d: duplicate problem line", line)
+			}
+			if _, err := fmt.Sscanf(text, "p cnf %d %d", &nVars, &nClauses); err != nil || nVars < 0 || nClauses < 0 {
+				return nil, fmt.Errorf("dimacs: line %d: invalid problem line '%s'; expected 'p cnf <vars> <clauses>'",
+					line, text)
+			}
+			continue
+		}
+		if nVars < 0 {
+			return nil, fmt.Errorf("dimacs: line %d: clause before problem line", line)
+		}
+		// Clauses are terminated by 0 and may span several lines.
+		for _, field := range strings.Fields(text) {
+			n, err := strconv.Atoi(field)
+			if err != nil {
+				return nil, fmt.Errorf("dimacs: line %d: invalid literal '%s'", line, field)
+			}
+			if n == 0 {
+				clauses = append(clauses, cl)
+				cl = nil
+				continue
+			}
+			if n > nVars || -n > nVars {
+				return nil, fmt.Errorf("dimacs: line %d: literal %d is out of range for %d variables", line, n, nVars)
+			}
+			cl = append(cl, n)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if nVars < 0 {
+		return nil, errors.New("dimacs: missing problem line")
+	}
+	if cl != nil {
+		// Tolerate a missing 0 after the last clause.
+		clauses = append(clauses, cl)
+	}
+	if len(clauses) != nClauses {
+		return nil, fmt.Errorf("dimacs: expected %d clauses; found %d", nClauses, len(clauses))
+	}
+
+	// Name the variables without a recorded name.
+	var unnamed []int
+	for _, cl := range clauses {
+		for _, n := range cl {
+			if n < 0 {
+				n = -n
+			}
+			if _, ok := names[n]; !ok && !containsInt(unnamed, n) {
+				unnamed = append(unnamed, n)
+			}
+		}
+	}
+	sort.Ints(unnamed)
+	prefix := "x"
+	for clash := true; clash; {
+		clash = false
+		for _, n := range unnamed {
+			if _, ok := vars[prefix+strconv.Itoa(n)]; ok {
+				prefix = "_" + prefix
+				clash = true
+				break
+			}
+		}
+	}
+	for _, n := range unnamed {
+		names[n] = prefix + strconv.Itoa(n)
+	}
+
+	terms := make([]term, len(clauses))
+	for i, cl := range clauses {
+		terms[i] = make(term, len(cl))
+		for j, n := range cl {
+			if n < 0 {
+				terms[i][j] = literal{names[-n], true}
+			} else {
+				terms[i][j] = literal{names[n], false}
+			}
+		}
+	}
+	return termsToStmt(terms, OpAnd, OpOr, false), nil
+}
+
+// isValidName reports whether the given string is a valid name for an atomic statement, i.e. an identifier which is
+// not a keyword.
+func isValidName(name string) bool {
+	for i, r := range name {
+		if !isIdentChar(r) || (i == 0 && !isIdentStart(r)) {
+			return false
+		}
+	}
+	upper := strings.ToUpper(name)
+	_, keyword := keywords[upper]
+	_, ternary := ternaryKeywords[upper]
+	return name != "" && !keyword && !ternary
+}
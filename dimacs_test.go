@@ -0,0 +1,130 @@
+package vera
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestReadDIMACS(t *testing.T) {
+	input := `c An example with a clause spanning two lines.
+c var 1 a
+c var 3 carry_in
+p cnf 3 3
+1 -2 0
+2 3
+-1 0
+-3 0
+`
+	s, err := ReadDIMACS(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("error occurred while reading: %v", err)
+	}
+	expected := "((a | !x2) & ((x2 | carry_in) | !a)) & !carry_in"
+	if s.String() != expected {
+		t.Fatalf("expected '%s'; got '%s'", expected, s)
+	}
+}
+
+func TestReadDIMACSNames(t *testing.T) {
+	// x2 is taken by variable 1, so variable 2 must be named differently.
+	s, err := ReadDIMACS(strings.NewReader("c var 1 x2\np cnf 2 1\n1 2 0\n"))
+	if err != nil {
+		t.Fatalf("error occurred while reading: %v", err)
+	}
+	if s.String() != "x2 | _x2" {
+		t.Fatalf("expected 'x2 | _x2'; got '%s'", s)
+	}
+	for input, expected := range map[string]string{
+		"p cnf 0 0\n":         "1",
+		"p cnf 1 2\n1 0\n0\n": "0",
+	} {
+		s, err := ReadDIMACS(strings.NewReader(input))
+		if err != nil {
+			t.Fatalf("error occurred while reading: %v (input: %q)", err, input)
+		}
+		if s.String() != expected {
+			t.Fatalf("expected '%s' for %q; got '%s'", expected, input, s)
+		}
+	}
+}
+
+func TestReadDIMACSError(t *testing.T) {
+	for _, input := range []string{
+		"1 2 0\n",
+		"p cnf 2 1\n1 3 0\n",
+		"p cnf 2 2\n1 2 0\n",
+		"p cnf 2 1\n1 x 0\n",
+		"p dnf 2 1\n1 2 0\n",
+		"c var 1 and\np cnf 1 1\n1 0\n",
+		"c var 1 a\nc var 2 a\np cnf 2 1\n1 2 0\n",
+		"c var 1 a\nc var 1 b\np cnf 1 1\n1 0\n",
+	} {
+		if _, err := ReadDIMACS(strings.NewReader(input)); err == nil {
+			t.Fatalf("expected an error for %q", input)
+		}
+	}
+	// Repeating the same name for a variable is harmless, but giving it a second name is not.
+	if _, err := ReadDIMACS(strings.NewReader("c var 1 a\nc var 1 a\np cnf 1 1\n1 0\n")); err != nil {
+		t.Fatalf("error occurred while reading: %v", err)
+	}
+	_, err := ReadDIMACS(strings.NewReader("p cnf 1 1\nc var 1 a\nc var 1 b\n1 0\n"))
+	expected := "dimacs: line 3: variable 1 is named both 'a' and 'b'"
+	if err == nil || err.Error() != expected {
+		t.Fatalf("expected error %q; got %v", expected, err)
+	}
+}
+
+func TestWriteDIMACS(t *testing.T) {
+	s, _, err := ParseWithOptions("(b | !a) & (a | c) & !c", Options{Precedence: Standard})
+	if err != nil {
+		t.Fatalf("error occurred while parsing: %v", err)
+	}
+	var sb strings.Builder
+	if err := WriteDIMACS(s, &sb); err != nil {
+		t.Fatalf("error occurred while writing: %v", err)
+	}
+	// The Stmt is already in CNF, so no auxiliary variables are needed.
+	expected := `c var 1 a
+c var 2 b
+c var 3 c
+p cnf 3 3
+2 -1 0
+1 3 0
+-3 0
+`
+	if sb.String() != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, sb.String())
+	}
+}
+
+func TestWriteDIMACSRoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 500; i++ {
+		s := randomStmt(r, 5)
+		var sb strings.Builder
+		if err := WriteDIMACS(s, &sb); err != nil {
+			t.Fatalf("error occurred while writing: %v", err)
+		}
+		read, err := ReadDIMACS(strings.NewReader(sb.String()))
+		if err != nil {
+			t.Fatalf("error occurred while reading: %v\n%s", err, sb.String())
+		}
+		if _, sat := Satisfiable(read); sat != bruteSatisfiable(s) {
+			t.Fatalf("expected satisfiable=%t for DIMACS of %s:\n%s", !sat, s, sb.String())
+		}
+		// A CNF should be read back as an equivalent Stmt.
+		cnf := ToCNF(s)
+		sb.Reset()
+		if err := WriteDIMACS(cnf, &sb); err != nil {
+			t.Fatalf("error occurred while writing: %v", err)
+		}
+		read, err = ReadDIMACS(strings.NewReader(sb.String()))
+		if err != nil {
+			t.Fatalf("error occurred while reading: %v\n%s", err, sb.String())
+		}
+		if equiv, _ := Equivalent(cnf, read); !equiv {
+			t.Fatalf("expected %s to be equivalent to %s", read, cnf)
+		}
+	}
+}
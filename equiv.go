@@ -0,0 +1,12 @@
+package vera
+
+// Equivalent reports whether the two given Stmts are logically equivalent, i.e. whether they have the same value for
+// every set of truth values. The Stmts need not have the same atomic statements; the returned Truth is over the union
+// of their atomic statements (see TruthFor). If the Stmts are not equivalent, the Truth is a set of truth values at
+// which they differ; otherwise, every truth value in it is false.
+// Like ClassifyWitness, small Stmts are compared by iterating over every set of truth values, and larger ones with the
+// SAT solver.
+func Equivalent(a Stmt, b Stmt) (bool, Truth) {
+	class, _, counterexample := ClassifyWitness(Binary{OpBicond, a, b})
+	return class == Tautology, counterexample
+}
@@ -0,0 +1,38 @@
+package vera
+
+import "testing"
+
+func TestEquivalent(t *testing.T) {
+	type testCase struct {
+		a        string
+		b        string
+		expected bool
+	}
+	for _, c := range []testCase{
+		{"a > b", "!a | b", true},
+		{"a > b", "b > a", false},
+		{"!(a & b)", "!a | !b", true},
+		{"a ↑ b", "!(a & b)", true},
+		{"if c then a else b", "(c & a) | (!c & b)", true},
+		{"a", "a & (b | !b)", true},
+		{"a", "a & b", false},
+		{"a | b", "c", false},
+		{"1", "a | !a", true},
+	} {
+		a, _, err := Parse(c.a)
+		if err != nil {
+			t.Fatalf("error occurred while parsing: %v (input: %s)", err, c.a)
+		}
+		b, _, err := Parse(c.b)
+		if err != nil {
+			t.Fatalf("error occurred while parsing: %v (input: %s)", err, c.b)
+		}
+		equiv, truth := Equivalent(a, b)
+		if equiv != c.expected {
+			t.Fatalf("expected %t for equivalence of '%s' and '%s'", c.expected, c.a, c.b)
+		}
+		if !equiv && a.Eval(truth) == b.Eval(truth) {
+			t.Fatalf("'%s' and '%s' do not differ at counterexample %s", c.a, c.b, truth)
+		}
+	}
+}
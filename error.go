@@ -0,0 +1,93 @@
+package vera
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// ParseError describes a syntax error in the input given to Parse or ParseWithOptions.
+type ParseError struct {
+	// Input is the original input string, exactly as it was passed to Parse.
+	Input string
+	// Offset is the byte offset in Input at which the error occurred.
+	Offset int
+	// Line and Column give the position of Offset in Input; both are 1-based. Column counts runes, not bytes.
+	Line   int
+	Column int
+	// Expected lists the tokens which would have been valid at Offset, in the form used by Error (e.g. "'&'" or
+	// "statement").
+	Expected []string
+	// Found is the token which was found at Offset instead (e.g. "'foo'" or "EOF").
+	Found string
+	// Reason, if not empty, explains why Found is not allowed at Offset, which Error reports in place of Expected.
+	Reason string
+}
+
+// newParseError creates a ParseError for the given position in the input, calculating the line and column.
+func newParseError(input string, offset int, found string, expected []string) *ParseError {
+	before := input[:offset]
+	lineStart := strings.LastIndexByte(before, '\n') + 1
+	return &ParseError{
+		Input:    input,
+		Offset:   offset,
+		Line:     strings.Count(before, "\n") + 1,
+		Column:   utf8.RuneCountInString(before[lineStart:]) + 1,
+		Expected: expected,
+		Found:    found,
+	}
+}
+
+func (e *ParseError) Error() string {
+	if e.Reason != "" {
+		return fmt.Sprintf("%d:%d: unexpected %s; %s", e.Line, e.Column, e.Found, e.Reason)
+	}
+	return fmt.Sprintf("%d:%d: unexpected %s; expected %s", e.Line, e.Column, e.Found, joinAlternatives(e.Expected))
+}
+
+// Diagnostic returns the line of the input containing the error followed by a line with a caret ('^') under the
+// offending column, e.g.:
+//
+//	a & foo b
+//	        ^
+func (e *ParseError) Diagnostic() string {
+	lineStart := strings.LastIndexByte(e.Input[:e.Offset], '\n') + 1
+	lineEnd := strings.IndexByte(e.Input[e.Offset:], '\n')
+	if lineEnd < 0 {
+		lineEnd = len(e.Input)
+	} else {
+		lineEnd += e.Offset
+	}
+	var sb strings.Builder
+	sb.WriteString(e.Input[lineStart:lineEnd])
+	sb.WriteByte('\n')
+	// Keep tabs in the padding so that the caret lines up with the line above when it is displayed.
+	for _, r := range e.Input[lineStart:e.Offset] {
+		if r == '\t' {
+			sb.WriteByte('\t')
+		} else {
+			sb.WriteByte(' ')
+		}
+	}
+	sb.WriteByte('^')
+	return sb.String()
+}
+
+// joinAlternatives formats a list of alternatives in English, e.g. "a, b, or c".
+func joinAlternatives(alts []string) string {
+	switch len(alts) {
+	case 0:
+		return "nothing"
+	case 1:
+		return alts[0]
+	case 2:
+		return alts[0] + " or " + alts[1]
+	default:
+		return strings.Join(alts[:len(alts)-1], ", ") + ", or " + alts[len(alts)-1]
+	}
+}
+
+// quoteToken formats a token from the input for use in ParseError.Found.
+func quoteToken(tok string) string {
+	return "'" + tok + "'"
+}
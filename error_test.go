@@ -0,0 +1,64 @@
+package vera
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseError(t *testing.T) {
+	type testCase struct {
+		input      string
+		offset     int
+		line       int
+		column     int
+		found      string
+		expected   []string
+		diagnostic string
+	}
+	for _, c := range []testCase{
+		{"a & foo b", 8, 1, 9, "'b'", []string{"'&'", "'|'", "'^'", "'>'", "'='", "'↑'", "'↓'", "'⊙'", "EOF"}, "a & foo b\n        ^"},
+		{"(a &", 4, 1, 5, "EOF", []string{"'!'", "'('", "'0'", "'1'", "statement"}, "(a &\n    ^"},
+		{"(a & b", 6, 1, 7, "EOF", []string{"')'", "'&'", "'|'", "'^'", "'>'", "'='", "'↑'", "'↓'", "'⊙'"}, "(a & b\n      ^"},
+		{"a)", 1, 1, 2, "')'", []string{"'&'", "'|'", "'^'", "'>'", "'='", "'↑'", "'↓'", "'⊙'", "EOF"}, "a)\n ^"},
+		{"a &\n\tb AND c", 7, 2, 4, "'AND'", []string{"EOF"}, "\tb AND c\n\t  ^"},
+		{"(a ∧ b ∨ c)", 9, 1, 8, "'∨'", []string{"')'"}, "(a ∧ b ∨ c)\n       ^"},
+		{"¬ ∧", 3, 1, 3, "'∧'", []string{"'!'", "'('", "'0'", "'1'", "statement"}, "¬ ∧\n  ^"},
+		{"if a then b", 11, 1, 12, "EOF", []string{"'else'"}, "if a then b\n           ^"},
+		{"if a & b | c then d else e", 9, 1, 10, "'|'", []string{"'then'"}, "if a & b | c then d else e\n         ^"},
+		{"(if a then b) else c", 12, 1, 13, "')'", []string{"'else'"}, "(if a then b) else c\n            ^"},
+		{"a then b", 2, 1, 3, "'then'", []string{"EOF"}, "a then b\n  ^"},
+		{"a $ b", 2, 1, 3, "'$'", []string{"'&'", "'|'", "'^'", "'>'", "'='", "'↑'", "'↓'", "'⊙'", "EOF"}, "a $ b\n  ^"},
+	} {
+		_, _, err := Parse(c.input)
+		var pe *ParseError
+		if !errors.As(err, &pe) {
+			t.Fatalf("expected a *ParseError; got %v (input: %q)", err, c.input)
+		}
+		if pe.Offset != c.offset || pe.Line != c.line || pe.Column != c.column {
+			t.Fatalf("expected offset %d at %d:%d; got offset %d at %d:%d (input: %q)",
+				c.offset, c.line, c.column, pe.Offset, pe.Line, pe.Column, c.input)
+		}
+		if pe.Found != c.found {
+			t.Fatalf("expected to find %s; got %s (input: %q)", c.found, pe.Found, c.input)
+		}
+		if len(pe.Expected) != len(c.expected) {
+			t.Fatalf("expected %v to be expected; got %v (input: %q)", c.expected, pe.Expected, c.input)
+		}
+		for i := range c.expected {
+			if pe.Expected[i] != c.expected[i] {
+				t.Fatalf("expected %v to be expected; got %v (input: %q)", c.expected, pe.Expected, c.input)
+			}
+		}
+		if d := pe.Diagnostic(); d != c.diagnostic {
+			t.Fatalf("expected diagnostic %q; got %q (input: %q)", c.diagnostic, d, c.input)
+		}
+	}
+}
+
+func TestParseErrorMessage(t *testing.T) {
+	_, _, err := Parse("a & foo b")
+	expected := "1:9: unexpected 'b'; expected '&', '|', '^', '>', '=', '↑', '↓', '⊙', or EOF"
+	if err == nil || err.Error() != expected {
+		t.Fatalf("expected error %q; got %v", expected, err)
+	}
+}
@@ -0,0 +1,229 @@
+package vera
+
+import "sort"
+
+// Simplify returns a sum-of-products Stmt which is equivalent to the given Stmt, using whichever of Minimize and
+// MinimizeHeuristic is practical for it. See SimplifyCover.
+func Simplify(s Stmt) Stmt {
+	cover, truth, _ := SimplifyCover(s)
+	return cover.Stmt(truth)
+}
+
+// SimplifyCover returns MinimalCover for Stmts with few enough atomic statements to enumerate every set of truth values
+// (the same limit used by ClassifyWitness), and HeuristicCover for larger Stmts. The boolean return value reports
+// whether the cover is guaranteed to be minimal, i.e. whether MinimalCover was used. SimplifyCover panics if the Stmt
+// has 64 or more atomic statements.
+func SimplifyCover(s Stmt) (Cover, Truth, bool) {
+	if len(TruthFor(s).Names) > maxEnumAtomics {
+		cover, truth := HeuristicCover(s)
+		return cover, truth, false
+	}
+	cover, truth := MinimalCover(s)
+	return cover, truth, true
+}
+
+// MinimizeHeuristic returns a sum-of-products Stmt which is equivalent to the given Stmt and usually minimal or close to
+// it. See HeuristicCover.
+func MinimizeHeuristic(s Stmt) Stmt {
+	cover, truth := HeuristicCover(s)
+	return cover.Stmt(truth)
+}
+
+// HeuristicCover returns a set of prime implicants of the given Stmt whose disjunction is equivalent to it, along with
+// the Truth over which the cubes are defined. Unlike MinimalCover, the result is not guaranteed to be minimal, but no
+// cube can be removed from it and no literal can be removed from any of its cubes.
+// The cover is found in the style of the Espresso logic minimizer, working with covers of cubes rather than individual
+// sets of truth values: starting from a DNF of the Stmt, the cubes are repeatedly expanded into prime implicants (as far
+// as a DNF of the negated Stmt allows), redundant cubes are removed, and the remaining cubes are reduced again to give
+// the next expansion a different starting point, until the cover stops improving. This makes it practical for Stmts
+// with many more atomic statements than MinimalCover, as long as the DNFs of the Stmt and its negation are not too
+// large. HeuristicCover panics if the Stmt has 64 or more atomic statements.
+func HeuristicCover(s Stmt) (Cover, Truth) {
+	truth := TruthFor(s)
+	if len(truth.Names) >= 64 {
+		panic("vera.HeuristicCover: cannot minimize a Stmt with 64 or more atomics")
+	}
+	on := termsToCover(dnfTerms(s), truth)
+	off := termsToCover(dnfTerms(Not{s}), truth)
+	if len(on) == 0 {
+		return nil, truth
+	}
+	if len(off) == 0 {
+		return Cover{{}}, truth
+	}
+	best := irredundant(expand(on, off))
+	for cover := best; ; {
+		cover = irredundant(expand(reduce(cover, len(truth.Names)), off))
+		if !cover.cost().less(best.cost()) {
+			break
+		}
+		best = cover
+	}
+	best.sort(truth)
+	return best, truth
+}
+
+// termsToCover converts terms (interpreted as cubes) to a Cover over the atomic statements in the given Truth.
+func termsToCover(terms []term, truth Truth) Cover {
+	cover := make(Cover, len(terms))
+	for i, t := range terms {
+		for _, l := range t {
+			bit := uint64(1) << truth.shiftMap[l.name]
+			cover[i].Mask |= bit
+			if !l.neg {
+				cover[i].Val |= bit
+			}
+		}
+	}
+	return cover
+}
+
+// intersects reports whether there is a set of truth values covered by both cubes, i.e. whether no atomic statement
+// appears positively in one and negated in the other.
+func (c Cube) intersects(o Cube) bool {
+	return c.Mask&o.Mask&(c.Val^o.Val) == 0
+}
+
+// contains reports whether every set of truth values covered by o is also covered by c.
+func (c Cube) contains(o Cube) bool {
+	return o.Mask&c.Mask == c.Mask && o.Val&c.Mask == c.Val
+}
+
+// supercube returns the smallest cube containing both cubes.
+func (c Cube) supercube(o Cube) Cube {
+	mask := c.Mask & o.Mask &^ (c.Val ^ o.Val)
+	return Cube{mask, c.Val & mask}
+}
+
+// intersectsAny reports whether the cube intersects any cube in the cover.
+func (c Cube) intersectsAny(cover Cover) bool {
+	for _, o := range cover {
+		if c.intersects(o) {
+			return true
+		}
+	}
+	return false
+}
+
+// cofactor returns the cofactor of the cover with respect to the given cube: the cover restricted to the sets of truth
+// values covered by the cube, with the atomic statements appearing in the cube removed.
+func (c Cover) cofactor(cube Cube) Cover {
+	var result Cover
+	for _, o := range c {
+		if o.intersects(cube) {
+			result = append(result, Cube{o.Mask &^ cube.Mask, o.Val &^ cube.Mask})
+		}
+	}
+	return result
+}
+
+// covers reports whether every set of truth values covered by the cube is covered by some cube in the cover.
+func (c Cover) covers(cube Cube) bool {
+	return c.cofactor(cube).tautology()
+}
+
+// tautology reports whether the cover covers every set of truth values. Covers which are unate (no atomic statement
+// appears both positively and negated) are decided directly; otherwise, the cover is split on a binate atomic
+// statement and both cofactors are checked.
+func (c Cover) tautology() bool {
+	var pos, neg uint64
+	for _, cube := range c {
+		if cube.Mask == 0 {
+			return true
+		}
+		pos |= cube.Mask & cube.Val
+		neg |= cube.Mask &^ cube.Val
+	}
+	binate := pos & neg
+	if binate == 0 {
+		// A unate cover without the universal cube misses the set of truth values opposite to every literal in it.
+		return false
+	}
+	bit := binate & -binate
+	return c.cofactor(Cube{bit, 0}).tautology() && c.cofactor(Cube{bit, bit}).tautology()
+}
+
+// cost returns the cost of the cover: first the number of cubes, then the number of literals.
+func (c Cover) cost() cost {
+	return cost{len(c), c.Literals()}
+}
+
+// expand expands each cube in the on-set cover into a prime implicant which does not intersect the off-set cover, and
+// removes the cubes which are then contained in another. Each cube is first expanded to contain as many other cubes as
+// possible, then any remaining literals which can be removed are.
+func expand(on Cover, off Cover) Cover {
+	cover := append(Cover(nil), on...)
+	// Expand the largest cubes first, since they are the least likely to be contained in another cube.
+	sort.SliceStable(cover, func(i, j int) bool {
+		return cover[i].Literals() < cover[j].Literals()
+	})
+	contained := make([]bool, len(cover))
+	var result Cover
+	for i, cube := range cover {
+		if contained[i] {
+			continue
+		}
+		for j := i + 1; j < len(cover); j++ {
+			if contained[j] {
+				continue
+			}
+			if super := cube.supercube(cover[j]); !super.intersectsAny(off) {
+				cube = super
+			}
+		}
+		for rest := cube.Mask; rest != 0; rest &= rest - 1 {
+			bit := rest & -rest
+			if raised := (Cube{cube.Mask &^ bit, cube.Val &^ bit}); !raised.intersectsAny(off) {
+				cube = raised
+			}
+		}
+		for j := i + 1; j < len(cover); j++ {
+			if cube.contains(cover[j]) {
+				contained[j] = true
+			}
+		}
+		result = append(result, cube)
+	}
+	return result
+}
+
+// irredundant removes cubes from the cover which are covered by the remaining cubes, trying the smallest cubes first.
+func irredundant(cover Cover) Cover {
+	result := append(Cover(nil), cover...)
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Literals() > result[j].Literals()
+	})
+	for i := 0; i < len(result); {
+		rest := append(append(Cover(nil), result[:i]...), result[i+1:]...)
+		if rest.covers(result[i]) {
+			result = rest
+		} else {
+			i++
+		}
+	}
+	return result
+}
+
+// reduce shrinks each cube in the cover (of functions of n atomic statements) as far as possible while the cover still
+// covers the same sets of truth values, trying the largest cubes first. A cube can be restricted to one value of an
+// atomic statement if the sets of truth values it covers with the other value are covered by the other cubes.
+func reduce(cover Cover, n int) Cover {
+	result := append(Cover(nil), cover...)
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Literals() < result[j].Literals()
+	})
+	for i := range result {
+		rest := append(append(Cover(nil), result[:i]...), result[i+1:]...)
+		cube := result[i]
+		for free := (uint64(1)<<n - 1) &^ cube.Mask; free != 0; free &= free - 1 {
+			bit := free & -free
+			if rest.covers(Cube{cube.Mask | bit, cube.Val}) {
+				cube = Cube{cube.Mask | bit, cube.Val | bit}
+			} else if rest.covers(Cube{cube.Mask | bit, cube.Val | bit}) {
+				cube = Cube{cube.Mask | bit, cube.Val}
+			}
+		}
+		result[i] = cube
+	}
+	return result
+}
@@ -0,0 +1,93 @@
+package vera
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestMinimizeHeuristic(t *testing.T) {
+	type testCase struct {
+		input    string
+		expected string
+	}
+	for _, c := range []testCase{
+		{"(a & b) | (a & !b)", "a"},
+		{"(a & b) | (!a & c) | (b & c)", "(a & b) | (!a & c)"},
+		{"a | !a", "1"},
+		{"a & !a", "0"},
+		{"a > b", "!a | b"},
+		{"(a & !b & c) | (!a & b) | (a & b) | (a & !c)", "a | b"},
+	} {
+		s, _, err := ParseWithOptions(c.input, Options{Precedence: Standard})
+		if err != nil {
+			t.Fatalf("error occurred while parsing: %v (input: %s)", err, c.input)
+		}
+		if actual := MinimizeHeuristic(s).String(); actual != c.expected {
+			t.Fatalf("expected '%s' for minimization of '%s'; got '%s'", c.expected, c.input, actual)
+		}
+	}
+}
+
+func TestHeuristicCoverRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 500; i++ {
+		s := randomStmt(r, 5)
+		cover, truth := HeuristicCover(s)
+		if equiv, _ := Equivalent(s, cover.Stmt(truth)); !equiv {
+			t.Fatalf("expected cover %s to be equivalent to %s", cover.Stmt(truth), s)
+		}
+		for j, cube := range cover {
+			for rest := cube.Mask; rest != 0; rest &= rest - 1 {
+				b := rest & -rest
+				if implies(Cube{cube.Mask &^ b, cube.Val &^ b}, s, truth) {
+					t.Fatalf("cube %s is not a prime implicant of %s", cube.Pattern(truth), s)
+				}
+			}
+			rest := append(append(Cover(nil), cover[:j]...), cover[j+1:]...)
+			if equiv, _ := Equivalent(s, rest.Stmt(truth)); equiv {
+				t.Fatalf("cube %s is redundant in cover %s of %s", cube.Pattern(truth), cover.Stmt(truth), s)
+			}
+		}
+	}
+}
+
+func TestHeuristicCoverWide(t *testing.T) {
+	// Each pair of cubes a_i & b_i & c_i and a_i & b_i & !c_i combines into a_i & b_i, over 24 atomic statements in total.
+	var sb strings.Builder
+	for i := 0; i < 8; i++ {
+		if i > 0 {
+			sb.WriteString(" | ")
+		}
+		_, _ = fmt.Fprintf(&sb, "(a%d & b%d & c%d) | (a%d & b%d & !c%d)", i, i, i, i, i, i)
+	}
+	s, _, err := ParseWithOptions(sb.String(), Options{Precedence: Standard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cover, truth := HeuristicCover(s)
+	if len(cover) != 8 || cover.Literals() != 16 {
+		t.Fatalf("expected a cover of 8 cubes and 16 literals; got %s", cover.Stmt(truth))
+	}
+	if equiv, _ := Equivalent(s, cover.Stmt(truth)); !equiv {
+		t.Fatalf("expected cover %s to be equivalent to %s", cover.Stmt(truth), s)
+	}
+}
+
+func TestSimplifyCover(t *testing.T) {
+	// a0 & a1 & ... & an is already minimal, so both minimizers find the same cover.
+	for _, n := range []int{maxEnumAtomics, maxEnumAtomics + 1} {
+		var s Stmt = Var("a0")
+		for i := 1; i < n; i++ {
+			s = Binary{OpAnd, s, Var(fmt.Sprintf("a%d", i))}
+		}
+		cover, _, exact := SimplifyCover(s)
+		if exact != (n <= maxEnumAtomics) {
+			t.Fatalf("expected exact to be %t for %d atomics", n <= maxEnumAtomics, n)
+		}
+		if len(cover) != 1 || cover.Literals() != n {
+			t.Fatalf("expected one cube of %d literals for %d atomics; got %v", n, n, cover)
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package vera
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"io"
+	"strings"
+)
+
+// GoOptions configures the code generated by GenerateGoWithOptions.
+type GoOptions struct {
+	// Package, if not empty, makes the output a complete Go source file in the named package, with a "Code generated"
+	// header so that it can be the output of a go:generate command.
+	Package string
+	// Struct, if not empty, is the name of a struct type to declare with a bool field for each atomic statement, which
+	// the function takes as its only argument instead of a bool argument for each atomic statement.
+	Struct string
+}
+
+// GenerateGo writes the source code of a Go function with the given name which takes a bool argument for each atomic
+// statement of the given Stmt (in lexicographic order) and returns the value of the Stmt, using the short-circuit
+// operators && and || for AND and OR. The output is formatted with gofmt. See GenerateGoWithOptions.
+func GenerateGo(s Stmt, funcName string, w io.Writer) error {
+	return GenerateGoWithOptions(s, funcName, w, GoOptions{})
+}
+
+// GenerateGoWithOptions is like GenerateGo, but can also generate a complete source file or a function taking a struct
+// argument (see GoOptions).
+// Atomic statements whose names are Go keywords or the predeclared identifiers used by the generated code (e.g. "type"
+// or "true") are renamed by appending underscores, and the fields of a struct are exported by capitalizing their names
+// (or prefixing them with "X" if they start with an underscore). An error is returned if the function or struct name
+// is not a usable identifier or if they are the same, since the generated code would not compile.
+func GenerateGoWithOptions(s Stmt, funcName string, w io.Writer, opts GoOptions) error {
+	if !token.IsIdentifier(funcName) {
+		return fmt.Errorf("invalid Go function name '%s'", funcName)
+	}
+	if opts.Package != "" && !token.IsIdentifier(opts.Package) {
+		return fmt.Errorf("invalid Go package name '%s'", opts.Package)
+	}
+	if opts.Struct != "" && !token.IsIdentifier(opts.Struct) {
+		return fmt.Errorf("invalid Go type name '%s'", opts.Struct)
+	}
+	// The function and struct are declared in the same scope, and the generated code refers to the reserved
+	// identifiers by their predeclared meanings.
+	if goReserved[funcName] {
+		return fmt.Errorf("reserved Go function name '%s'", funcName)
+	}
+	if goReserved[opts.Struct] {
+		return fmt.Errorf("reserved Go type name '%s'", opts.Struct)
+	}
+	if opts.Struct == funcName {
+		return fmt.Errorf("Go type name '%s' is the same as the function name", opts.Struct)
+	}
+	truth := TruthFor(s)
+	g := goGen{idents: make(map[string]string, len(truth.Names)), used: make(map[string]bool)}
+	// Keep the parameters from shadowing the function and struct, so that every name in the output is unambiguous.
+	g.used[funcName] = true
+	if opts.Struct != "" {
+		g.used[opts.Struct] = true
+	}
+	var names []string
+	for i := len(truth.Names) - 1; i >= 0; i-- {
+		name := truth.Names[i]
+		names = append(names, name)
+		g.idents[name] = g.unique(goIdent(name, opts.Struct != ""))
+	}
+	if opts.Package != "" {
+		fmt.Fprintf(&g.buf, "// Code generated by vera; DO NOT EDIT.\n\npackage %s\n\n", opts.Package)
+	}
+	var params string
+	if opts.Struct != "" {
+		fmt.Fprintf(&g.buf, "// %s holds the truth values of the atomic statements of %s.\n", opts.Struct, s)
+		fmt.Fprintf(&g.buf, "type %s struct {\n", opts.Struct)
+		for _, name := range names {
+			fmt.Fprintf(&g.buf, "%s bool\n", g.idents[name])
+		}
+		g.buf.WriteString("}\n\n")
+		g.arg = g.unique("in")
+		params = g.arg + " " + opts.Struct
+	} else if len(names) > 0 {
+		idents := make([]string, len(names))
+		for i, name := range names {
+			idents[i] = g.idents[name]
+		}
+		params = strings.Join(idents, ", ") + " bool"
+	}
+	fmt.Fprintf(&g.buf, "// %s returns the value of %s.\n", funcName, s)
+	fmt.Fprintf(&g.buf, "func %s(%s) bool {\n", funcName, params)
+	g.ret(s)
+	g.buf.WriteString("}\n")
+	src, err := format.Source(g.buf.Bytes())
+	if err != nil {
+		// Not possible unless there is a bug in goGen.
+		panic(fmt.Sprintf("vera.GenerateGo: generated invalid code: %v", err))
+	}
+	_, err = w.Write(src)
+	return err
+}
+
+// goReserved are the predeclared identifiers which the generated code refers to, so they cannot be shadowed by the
+// names of atomic statements (along with "_", which cannot be read).
+var goReserved = map[string]bool{"true": true, "false": true, "bool": true, "_": true}
+
+// goIdent returns an identifier for the atomic statement with the given name which is not a Go keyword or reserved
+// identifier, exporting it if it is to be a struct field.
+func goIdent(name string, field bool) string {
+	if field {
+		if name[0] == '_' {
+			return "X" + name
+		}
+		return strings.ToUpper(name[:1]) + name[1:]
+	}
+	if token.IsKeyword(name) || goReserved[name] {
+		return name + "_"
+	}
+	return name
+}
+
+// goGen holds the state of GenerateGoWithOptions.
+type goGen struct {
+	buf bytes.Buffer
+	// idents maps the names of atomic statements to their identifiers (or struct fields) in the generated code.
+	idents map[string]string
+	// used is the set of identifiers in the generated function.
+	used map[string]bool
+	// arg is the name of the struct argument, if any.
+	arg string
+	// temps is the number of temporary variables declared so far.
+	temps int
+}
+
+// unique returns the given identifier with enough underscores appended that it is not yet used, and marks it as used.
+func (g *goGen) unique(ident string) string {
+	for g.used[ident] {
+		ident += "_"
+	}
+	g.used[ident] = true
+	return ident
+}
+
+// ret writes statements returning the value of the given Stmt. Conditional expressions at the top level become if
+// statements.
+func (g *goGen) ret(s Stmt) {
+	if ite, ok := s.(IfThenElse); ok {
+		fmt.Fprintf(&g.buf, "if %s {\n", g.expr(ite.Cond))
+		g.ret(ite.Then)
+		g.buf.WriteString("}\n")
+		g.ret(ite.Else)
+		return
+	}
+	fmt.Fprintf(&g.buf, "return %s\n", g.expr(s))
+}
+
+// expr returns a Go expression for the value of the given Stmt. Go has no conditional operator, so each conditional
+// expression is assigned to a temporary variable by an if statement, which is written before the expression is
+// returned.
+func (g *goGen) expr(s Stmt) string {
+	switch s := s.(type) {
+	case Const:
+		if s {
+			return "true"
+		}
+		return "false"
+	case Var:
+		if g.arg != "" {
+			return g.arg + "." + g.idents[string(s)]
+		}
+		return g.idents[string(s)]
+	case Not:
+		return "!" + g.operand(s.X)
+	case Binary:
+		if not, ok := s.Left.(Not); ok && s.Op == OpCond {
+			// Avoid a double negation: !a > b is a || b.
+			left, right := g.operand(not.X), g.operand(s.Right)
+			return left + " || " + right
+		}
+		left, right := g.operand(s.Left), g.operand(s.Right)
+		switch s.Op {
+		case OpAnd:
+			return left + " && " + right
+		case OpOr:
+			return left + " || " + right
+		case OpXor:
+			return left + " != " + right
+		case OpCond:
+			return "!" + left + " || " + right
+		case OpBicond, OpXnor:
+			return left + " == " + right
+		case OpNand:
+			return "!(" + left + " && " + right + ")"
+		case OpNor:
+			return "!(" + left + " || " + right + ")"
+		default:
+			panic(fmt.Sprintf("invalid Op %d", s.Op))
+		}
+	case IfThenElse:
+		g.temps++
+		temp := g.unique(fmt.Sprintf("t%d", g.temps))
+		fmt.Fprintf(&g.buf, "var %s bool\n", temp)
+		fmt.Fprintf(&g.buf, "if %s {\n", g.expr(s.Cond))
+		fmt.Fprintf(&g.buf, "%s = %s\n", temp, g.expr(s.Then))
+		g.buf.WriteString("} else {\n")
+		fmt.Fprintf(&g.buf, "%s = %s\n", temp, g.expr(s.Else))
+		g.buf.WriteString("}\n")
+		return temp
+	default:
+		panic(fmt.Sprintf("vera.GenerateGo: unexpected Stmt type %T", s))
+	}
+}
+
+// operand returns a Go expression for the value of the given Stmt which can be an operand of a Go operator.
+func (g *goGen) operand(s Stmt) string {
+	e := g.expr(s)
+	// NAND and NOR are already negated parenthesized expressions.
+	if b, ok := s.(Binary); ok && b.Op != OpNand && b.Op != OpNor {
+		return "(" + e + ")"
+	}
+	return e
+}
@@ -0,0 +1,123 @@
+package vera
+
+import (
+	"go/ast"
+	goparser "go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+	"testing"
+)
+
+func TestGenerateGo(t *testing.T) {
+	cases := []struct {
+		input    string
+		opts     GoOptions
+		expected string
+	}{
+		{"(a & b) | (!c > d)", GoOptions{}, `// Rule returns the value of (a & b) | (!c > d).
+func Rule(a, b, c, d bool) bool {
+	return (a && b) || (c || d)
+}
+`},
+		{"(a ^ b) = ((a ↑ b) ↓ !c)", GoOptions{}, `// Rule returns the value of (a ^ b) = ((a ↑ b) ↓ !c).
+func Rule(a, b, c bool) bool {
+	return (a != b) == !(!(a && b) || !c)
+}
+`},
+		{"if type then (if x then 1 else y) else _", GoOptions{}, `// Rule returns the value of if type then if x then 1 else y else _.
+func Rule(__, type_, x, y bool) bool {
+	if type_ {
+		if x {
+			return true
+		}
+		return y
+	}
+	return __
+}
+`},
+		{"x & (if _x then y else !x)", GoOptions{Package: "rules", Struct: "Input"}, `// Code generated by vera; DO NOT EDIT.
+
+package rules
+
+// Input holds the truth values of the atomic statements of x & (if _x then y else !x).
+type Input struct {
+	X_x bool
+	X   bool
+	Y   bool
+}
+
+// Rule returns the value of x & (if _x then y else !x).
+func Rule(in Input) bool {
+	var t1 bool
+	if in.X_x {
+		t1 = in.Y
+	} else {
+		t1 = !in.X
+	}
+	return in.X && t1
+}
+`},
+	}
+	for _, c := range cases {
+		stmt, _, err := Parse(c.input)
+		if err != nil {
+			t.Fatalf("error occurred while parsing: %v (input: %s)", err, c.input)
+		}
+		var sb strings.Builder
+		if err := GenerateGoWithOptions(stmt, "Rule", &sb, c.opts); err != nil {
+			t.Fatalf("error occurred while generating: %v (input: %s)", err, c.input)
+		}
+		if sb.String() != c.expected {
+			t.Fatalf("expected:\n%s\ngot:\n%s", c.expected, sb.String())
+		}
+	}
+}
+
+func TestGenerateGoInvalidNames(t *testing.T) {
+	for _, opts := range []GoOptions{{Package: "func"}, {Struct: "1x"}, {Struct: "bool"}, {Struct: "Rule"}} {
+		if err := GenerateGoWithOptions(Var("a"), "Rule", &strings.Builder{}, opts); err == nil {
+			t.Fatalf("expected error for options %+v", opts)
+		}
+	}
+	for _, funcName := range []string{"my-rule", "true"} {
+		if err := GenerateGo(Var("a"), funcName, &strings.Builder{}); err == nil {
+			t.Fatalf("expected error for function name %s", funcName)
+		}
+	}
+}
+
+func TestGenerateGoTypeCheck(t *testing.T) {
+	type testCase struct {
+		input string
+		opts  GoOptions
+	}
+	// The atomic statements are named after the function, the struct, its argument, and a temporary variable.
+	const input = "(Rule & Input) | (if in then t1 else !bool)"
+	for _, c := range []testCase{
+		{input, GoOptions{}},
+		{input, GoOptions{Struct: "Input"}},
+		{input, GoOptions{Struct: "in"}},
+		{input, GoOptions{Struct: "t1"}},
+		{"if a then (if b then c else d) else e", GoOptions{Struct: "Input"}},
+	} {
+		stmt, _, err := Parse(c.input)
+		if err != nil {
+			t.Fatalf("error occurred while parsing: %v (input: %s)", err, c.input)
+		}
+		var sb strings.Builder
+		sb.WriteString("package rules\n\n")
+		if err := GenerateGoWithOptions(stmt, "Rule", &sb, c.opts); err != nil {
+			t.Fatalf("error occurred while generating: %v (input: %s)", err, c.input)
+		}
+		fset := token.NewFileSet()
+		f, err := goparser.ParseFile(fset, "rule.go", sb.String(), 0)
+		if err != nil {
+			t.Fatalf("error occurred while parsing the output: %v (input: %s)", err, c.input)
+		}
+		if _, err := (&types.Config{}).Check("rules", fset, []*ast.File{f}, nil); err != nil {
+			t.Fatalf("generated code does not compile: %v (input: %s, options: %+v):\n%s", err, c.input, c.opts,
+				sb.String())
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package vera
+
+import (
+	"errors"
+	"fmt"
+	"github.com/fatih/color"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// RenderKMap writes a Karnaugh map for the given Stmt/Truth pair to the given io.Writer. The appearance of the map is
+// dictated by the given CharSet and colorize parameter, as with RenderTT.
+// The first half of the atomic statements (in lexicographic order, rounded down) label the rows and the rest label the
+// columns; both are ordered by the reflected Gray code so that adjacent cells (including those at opposite edges) differ
+// in exactly one truth value. Karnaugh maps are only drawn for 2 to 6 atomic statements.
+func RenderKMap(stmt Stmt, truth Truth, out io.Writer, cs *CharSet, colorize bool) error {
+	return RenderKMapWithGroups(stmt, truth, nil, out, cs, colorize)
+}
+
+// RenderKMapWithGroups writes a Karnaugh map in the same style as RenderKMap, but also marks the given groups of cells,
+// which are usually the prime implicants of a minimal cover of the Stmt (see MinimalCover). The cubes must be over the
+// atomic statements of the given Truth. Each group is assigned a letter, which is shown in every cell of the group, and
+// a legend giving the term for each letter is written below the map.
+func RenderKMapWithGroups(stmt Stmt, truth Truth, groups Cover, out io.Writer, cs *CharSet, colorize bool) error {
+	n := len(truth.Names)
+	if n < 2 || n > 6 {
+		return errors.New("can only make a Karnaugh map with 2 to 6 atomics")
+	}
+	if len(groups) > 26 {
+		return errors.New("cannot mark more than 26 groups in a Karnaugh map")
+	}
+	color.NoColor = !colorize
+	// truth.Names is in descending order, so the lexicographically first atomic statements are at the end.
+	rowNames := reverseNames(truth.Names[n-n/2:])
+	colNames := reverseNames(truth.Names[:n-n/2])
+	rowLabels := grayLabels(len(rowNames))
+	colLabels := grayLabels(len(colNames))
+
+	// Evaluate every cell first so the column widths can account for the group letters.
+	values := make([][]bool, len(rowLabels))
+	letters := make([][]string, len(rowLabels))
+	maxLetters := 0
+	for r, rowLabel := range rowLabels {
+		values[r] = make([]bool, len(colLabels))
+		letters[r] = make([]string, len(colLabels))
+		for c, colLabel := range colLabels {
+			setLabel(&truth, rowNames, rowLabel)
+			setLabel(&truth, colNames, colLabel)
+			values[r][c] = stmt.Eval(truth)
+			for i, g := range groups {
+				if g.Covers(truth.Val) {
+					letters[r][c] += string(rune('A' + i))
+				}
+			}
+			if l := len(letters[r][c]); l > maxLetters {
+				maxLetters = l
+			}
+		}
+	}
+
+	corner := strings.Join(rowNames, ",") + `\` + strings.Join(colNames, ",")
+	widths := make([]int, len(colLabels)+1)
+	widths[0] = utf8.RuneCountInString(corner)
+	cellWidth := len(colLabels[0])
+	if maxLetters > 0 {
+		// The value, a space, and the letters.
+		if w := 2 + maxLetters; w > cellWidth {
+			cellWidth = w
+		}
+	}
+	for i := range colLabels {
+		widths[i+1] = cellWidth
+	}
+
+	if err := printGridLine(widths, out, cs.RowSep, cs.TLCorner, cs.TopT, cs.TRCorner); err != nil {
+		return err
+	}
+	header := make([]string, len(widths))
+	header[0] = corner
+	for i, label := range colLabels {
+		header[i+1] = centerText(label, cellWidth)
+	}
+	if err := printGridRow(header, out, cs); err != nil {
+		return err
+	}
+	for r, rowLabel := range rowLabels {
+		if err := printGridLine(widths, out, cs.RowSep, cs.LeftT, cs.Center, cs.RightT); err != nil {
+			return err
+		}
+		cells := make([]string, len(widths))
+		cells[0] = centerText(rowLabel, widths[0])
+		for c := range colLabels {
+			cells[c+1] = kmapCell(values[r][c], letters[r][c], cellWidth)
+		}
+		if err := printGridRow(cells, out, cs); err != nil {
+			return err
+		}
+	}
+	if err := printGridLine(widths, out, cs.RowSep, cs.BLCorner, cs.BottomT, cs.BRCorner); err != nil {
+		return err
+	}
+	for i, g := range groups {
+		if _, err := fmt.Fprintf(out, "%c: %s\n", 'A'+i, g.Stmt(truth)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reverseNames returns a reversed copy of the given names.
+func reverseNames(names []string) []string {
+	reversed := make([]string, len(names))
+	for i, name := range names {
+		reversed[len(names)-1-i] = name
+	}
+	return reversed
+}
+
+// grayLabels returns the labels of the rows or columns of a Karnaugh map for the given number of atomic statements,
+// i.e. the binary strings of that length in reflected Gray code order.
+func grayLabels(n int) []string {
+	labels := make([]string, 1<<n)
+	for i := range labels {
+		labels[i] = fmt.Sprintf("%0*b", n, i^(i>>1))
+	}
+	return labels
+}
+
+// setLabel sets the truth values of the given atomic statements to the digits of the given label.
+func setLabel(truth *Truth, names []string, label string) {
+	for i, name := range names {
+		truth.Set(name, label[i] == '1')
+	}
+}
+
+// kmapCell returns the contents of a cell in a Karnaugh map centered to the given width: the colored value, followed by
+// the letters of the groups containing it, if any.
+func kmapCell(val bool, letters string, width int) string {
+	text := "0"
+	if val {
+		text = "1"
+	}
+	if letters != "" {
+		text += " " + letters
+	}
+	cell := centerText(text, width)
+	i := strings.IndexAny(cell, "01")
+	return cell[:i] + colorBool(val, 1) + cell[i+1:]
+}
+
+// printGridLine draws a horizontal line across columns of the given widths.
+func printGridLine(widths []int, out io.Writer, rowSep string, l string, m string, r string) error {
+	var sb strings.Builder
+	sb.WriteString(l)
+	for i, w := range widths {
+		if i > 0 {
+			sb.WriteString(m)
+		}
+		sb.WriteString(strings.Repeat(rowSep, w))
+	}
+	sb.WriteString(r)
+	_, err := fmt.Fprintln(out, sb.String())
+	return err
+}
+
+// printGridRow prints a row of cells, which must already be padded to the widths of their columns.
+func printGridRow(cells []string, out io.Writer, cs *CharSet) error {
+	var sb strings.Builder
+	for _, cell := range cells {
+		sb.WriteString(cs.ColSep)
+		sb.WriteString(cell)
+	}
+	sb.WriteString(cs.ColSep)
+	_, err := fmt.Fprintln(out, sb.String())
+	return err
+}
@@ -0,0 +1,69 @@
+package vera
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderKMap(t *testing.T) {
+	stmt, truth, err := ParseWithOptions("(a & b) | (c & !d)", Options{Precedence: Standard})
+	if err != nil {
+		t.Fatalf("error occurred while parsing: %v", err)
+	}
+	var sb strings.Builder
+	if err := RenderKMap(stmt, truth, &sb, ASCIIBoxCS, false); err != nil {
+		t.Fatalf("error occurred while rendering: %v", err)
+	}
+	expected := `+-------+--+--+--+--+
+|a,b\c,d|00|01|11|10|
++-------+--+--+--+--+
+|  00   |0 |0 |0 |1 |
++-------+--+--+--+--+
+|  01   |0 |0 |0 |1 |
++-------+--+--+--+--+
+|  11   |1 |1 |1 |1 |
++-------+--+--+--+--+
+|  10   |0 |0 |0 |1 |
++-------+--+--+--+--+
+`
+	if sb.String() != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, sb.String())
+	}
+}
+
+func TestRenderKMapWithGroups(t *testing.T) {
+	stmt, truth, err := ParseWithOptions("(a & b) | (!a & c) | (b & c)", Options{Precedence: Standard})
+	if err != nil {
+		t.Fatalf("error occurred while parsing: %v", err)
+	}
+	cover, _ := MinimalCover(stmt)
+	var sb strings.Builder
+	if err := RenderKMapWithGroups(stmt, truth, cover, &sb, PrettyBoxCS, false); err != nil {
+		t.Fatalf("error occurred while rendering: %v", err)
+	}
+	expected := `┌─────┬───┬───┬───┬───┐
+│a\b,c│00 │01 │11 │10 │
+├─────┼───┼───┼───┼───┤
+│  0  │ 0 │1 B│1 B│ 0 │
+├─────┼───┼───┼───┼───┤
+│  1  │ 0 │ 0 │1 A│1 A│
+└─────┴───┴───┴───┴───┘
+A: a & b
+B: !a & c
+`
+	if sb.String() != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, sb.String())
+	}
+}
+
+func TestRenderKMapSize(t *testing.T) {
+	for _, input := range []string{"a", "a & b & c & d & e & f & g"} {
+		stmt, truth, err := ParseWithOptions(input, Options{Precedence: Standard})
+		if err != nil {
+			t.Fatalf("error occurred while parsing: %v (input: %s)", err, input)
+		}
+		if err := RenderKMap(stmt, truth, &strings.Builder{}, ASCIIBoxCS, false); err == nil {
+			t.Fatalf("expected an error for a Karnaugh map of '%s'", input)
+		}
+	}
+}
@@ -1,10 +1,9 @@
 package vera
 
 import (
-	"errors"
-	"fmt"
 	"strings"
 	"unicode"
+	"unicode/utf8"
 )
 
 const (
@@ -14,8 +13,47 @@ const (
 	xorSym    = '^'
 	condSym   = '>'
 	bicondSym = '='
+	nandSym   = '↑'
+	norSym    = '↓'
+	xnorSym   = '⊙'
 )
 
+// symbolAliases maps alternate single-rune spellings of negation, the binary operators, and the constants to their
+// canonical symbols.
+var symbolAliases = map[rune]rune{
+	'~': negateSym,
+	'¬': negateSym,
+	'∧': andSym,
+	'∨': orSym,
+	'⊕': xorSym,
+	'→': condSym,
+	'↔': bicondSym,
+	'⊤': '1',
+	'⊥': '0',
+}
+
+// keywords maps keyword spellings of negation and the binary operators to their canonical symbols. Keywords are matched
+// case-insensitively, so the keys are uppercase.
+var keywords = map[string]rune{
+	"NOT":     negateSym,
+	"AND":     andSym,
+	"OR":      orSym,
+	"XOR":     xorSym,
+	"IMPLIES": condSym,
+	"IFF":     bicondSym,
+	"NAND":    nandSym,
+	"NOR":     norSym,
+	"XNOR":    xnorSym,
+}
+
+// ternaryKeywords maps the keywords of the ternary conditional expression ("if c then a else b") to their lexeme types.
+// Like keywords, they are matched case-insensitively.
+var ternaryKeywords = map[string]lexemeType{
+	"IF":   ltIf,
+	"THEN": ltThen,
+	"ELSE": ltElse,
+}
+
 type lexemeType byte
 
 func (lt lexemeType) String() string {
@@ -34,6 +72,12 @@ func (lt lexemeType) String() string {
 		return "CloseParen"
 	case ltStatement:
 		return "Statement"
+	case ltIf:
+		return "If"
+	case ltThen:
+		return "Then"
+	case ltElse:
+		return "Else"
 	default:
 		panic("lexemeType not added to String method!")
 	}
@@ -47,20 +91,30 @@ const (
 	ltOpenParen
 	ltCloseParen
 	ltStatement
+	ltIf
+	ltThen
+	ltElse
 )
 
+// lexeme is a single token of input. For lexemes other than statements, v holds the canonical symbol for the token
+// regardless of how it was spelled in the input (e.g. "∧", "&&", and "AND" all produce an Operator lexeme with v "&").
 type lexeme struct {
 	t lexemeType
-	v byte
+	v string
 }
 
+// lexerResult is a lexeme (or an error) sent by the lexer. start and end are the byte offsets of the lexeme in the
+// input string, which are needed to report errors since the canonical symbol in the lexeme may be spelled differently in
+// the input.
 type lexerResult struct {
-	l   lexeme
-	err error
+	l     lexeme
+	start int
+	end   int
+	err   error
 }
 
 // statefn is a state combined with an associated action. See Rob Pike's talk on lexical scanning.
-type statefn func(byte, *lexer) (lexemeType, statefn, error)
+type statefn func(rune, *lexer) (lexeme, statefn, error)
 
 type lexer struct {
 	input    string
@@ -68,28 +122,21 @@ type lexer struct {
 	nextIdx  int
 	nestCnt  int
 	allowEOF bool
-}
-
-// removeAllWS removes all the whitespace from a string and returns the new string, where whitespace is identified
-// according to unicode.IsSpace.
-func removeAllWS(str string) string {
-	var b strings.Builder
-	b.Grow(len(str))
-	for _, ch := range str {
-		if !unicode.IsSpace(ch) {
-			b.WriteRune(ch)
-		}
-	}
-	return b.String()
+	// tokStart is the byte offset in input of the first rune of the lexeme currently being lexed.
+	tokStart int
+	// expectStmt is true if the next lexeme is expected to be the start of a statement (i.e. the next statefn is
+	// lexStatement); it is used to report what was expected if EOF is reached unexpectedly.
+	expectStmt bool
 }
 
 // lex lexes the given string in a separate goroutine and outputs the resultant lexerResults over the returned channel.
 func lex(input string) chan lexerResult {
 	l := &lexer{
-		input: removeAllWS(input),
+		input: input,
 		// Arbitrary buffer size.
-		c:        make(chan lexerResult, 10),
-		allowEOF: false,
+		c:          make(chan lexerResult, 10),
+		allowEOF:   false,
+		expectStmt: true,
 	}
 	go l.run()
 	return l.c
@@ -98,38 +145,118 @@ func lex(input string) chan lexerResult {
 // run is the main loop for a lexer. It should be called in a separate goroutine.
 func (l *lexer) run() {
 	for sfn := lexStatement; sfn != nil; {
+		l.skipWS()
+		l.tokStart = l.nextIdx
 		n, eof := l.next()
 		if eof {
 			if !l.allowEOF {
-				l.c <- lexerResult{err: errors.New("unexpected EOF")}
+				var expected []string
+				if l.expectStmt {
+					expected = stmtExpected()
+				} else {
+					expected = l.opExpected()
+				}
+				l.c <- lexerResult{err: newParseError(l.input, l.tokStart, "EOF", expected)}
 			}
 			break
 		}
-		var lt lexemeType
+		var lm lexeme
 		var err error
-		lt, sfn, err = sfn(n, l)
+		lm, sfn, err = sfn(n, l)
 		if err != nil {
 			l.c <- lexerResult{err: err}
 			break
 		}
-		l.c <- lexerResult{l: lexeme{lt, n}}
+		switch lm.t {
+		case ltFalse, ltTrue, ltStatement, ltCloseParen:
+			l.expectStmt = false
+		default:
+			l.expectStmt = true
+		}
+		l.c <- lexerResult{l: lm, start: l.tokStart, end: l.nextIdx}
 	}
 	// Closing the channel without any errors implies EOF.
 	close(l.c)
 }
 
-// next returns the next byte in the input string. The boolean return value indicates if the end of the string was
-// reached (i.e. EOF); if it is true, the byte return value should be disregarded.
-func (l *lexer) next() (byte, bool) {
+// next returns the next rune in the input string. The boolean return value indicates if the end of the string was
+// reached (i.e. EOF); if it is true, the rune return value should be disregarded. Invalid UTF-8 is returned as
+// utf8.RuneError.
+func (l *lexer) next() (rune, bool) {
+	if l.nextIdx == len(l.input) {
+		return 0, true
+	}
+	next, w := utf8.DecodeRuneInString(l.input[l.nextIdx:])
+	l.nextIdx += w
+	return next, false
+}
+
+// peek returns the next rune in the input string without consuming it. The boolean return value has the same meaning
+// as for next.
+func (l *lexer) peek() (rune, bool) {
 	if l.nextIdx == len(l.input) {
 		return 0, true
 	}
-	// Indexing into string => we are not expecting UTF-8 chars with width > 1 byte.
-	next := l.input[l.nextIdx]
-	l.nextIdx++
+	next, _ := utf8.DecodeRuneInString(l.input[l.nextIdx:])
 	return next, false
 }
 
+// accept consumes the given string if the remaining input starts with it, returning whether it did so.
+func (l *lexer) accept(s string) bool {
+	if strings.HasPrefix(l.input[l.nextIdx:], s) {
+		l.nextIdx += len(s)
+		return true
+	}
+	return false
+}
+
+// skipWS advances past any whitespace in the input string, where whitespace is identified according to
+// unicode.IsSpace.
+func (l *lexer) skipWS() {
+	for r, eof := l.peek(); !eof && unicode.IsSpace(r); r, eof = l.peek() {
+		l.next()
+	}
+}
+
+// ident consumes the remainder of an identifier whose first rune has already been consumed, and returns the whole
+// identifier.
+func (l *lexer) ident() string {
+	for r, eof := l.peek(); !eof && isIdentChar(r); r, eof = l.peek() {
+		l.next()
+	}
+	return l.input[l.tokStart:l.nextIdx]
+}
+
+// errorf returns a *ParseError for the lexeme currently being lexed, which was found in place of one of the expected
+// tokens.
+func (l *lexer) errorf(expected []string) error {
+	return newParseError(l.input, l.tokStart, quoteToken(l.input[l.tokStart:l.nextIdx]), expected)
+}
+
+// stmtExpected returns the tokens which may start a statement, for use in a ParseError.
+func stmtExpected() []string {
+	return []string{quoteToken(string(negateSym)), "'('", "'0'", "'1'", "statement"}
+}
+
+// opExpected returns the tokens which may follow a statement, for use in a ParseError. A closing parenthesis is only
+// expected if there are unmatched opening parentheses; otherwise, EOF is expected.
+func (l *lexer) opExpected() []string {
+	ops := []string{
+		quoteToken(string(andSym)),
+		quoteToken(string(orSym)),
+		quoteToken(string(xorSym)),
+		quoteToken(string(condSym)),
+		quoteToken(string(bicondSym)),
+		quoteToken(string(nandSym)),
+		quoteToken(string(norSym)),
+		quoteToken(string(xnorSym)),
+	}
+	if l.nestCnt > 0 {
+		return append([]string{"')'"}, ops...)
+	}
+	return append(ops, "EOF")
+}
+
 // nest increments nestCnt and sets allowEOF as appropriate.
 func (l *lexer) nest() {
 	l.nestCnt++
@@ -148,42 +275,100 @@ func (l *lexer) denest() bool {
 	return true
 }
 
+// isIdentStart reports whether the given rune may begin the name of an atomic statement.
+func isIdentStart(r rune) bool {
+	return ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || r == '_'
+}
+
+// isIdentChar reports whether the given rune may appear after the first rune in the name of an atomic statement.
+func isIdentChar(r rune) bool {
+	return isIdentStart(r) || ('0' <= r && r <= '9')
+}
+
+// canonicalize replaces the given rune with its canonical symbol if it is an alias listed in symbolAliases.
+func canonicalize(r rune) rune {
+	if c, ok := symbolAliases[r]; ok {
+		return c
+	}
+	return r
+}
+
 // lexStatement is a statefn for parsing the start of a statement (this includes opening parentheses, "0", "1", and
-// letters) or negation.
-func lexStatement(n byte, l *lexer) (lexemeType, statefn, error) {
+// identifiers) or negation. Identifiers match [A-Za-z_][A-Za-z0-9_]*; the remainder of an identifier is consumed here
+// so that the emitted lexeme holds the whole name.
+func lexStatement(n rune, l *lexer) (lexeme, statefn, error) {
 	// By default, allow EOF if there are no unmatched parentheses.
 	// Some branches in the below switch set the allowEOF flag based on other conditions.
 	l.allowEOF = l.nestCnt == 0
-	switch n {
+	switch canonicalize(n) {
 	case negateSym:
 		l.allowEOF = false
-		return ltNegate, lexStatement, nil
+		return lexeme{ltNegate, string(negateSym)}, lexStatement, nil
 	case '(':
 		l.nest()
-		return ltOpenParen, lexStatement, nil
+		return lexeme{ltOpenParen, "("}, lexStatement, nil
 	case '0':
-		return ltFalse, lexOperator, nil
+		return lexeme{ltFalse, "0"}, lexOperator, nil
 	case '1':
-		return ltTrue, lexOperator, nil
+		return lexeme{ltTrue, "1"}, lexOperator, nil
 	}
-	if ('a' <= n && n <= 'z') || ('A' <= n && n <= 'Z') {
-		return ltStatement, lexOperator, nil
+	if isIdentStart(n) {
+		name := l.ident()
+		if strings.ToUpper(name) == "IF" {
+			l.allowEOF = false
+			return lexeme{ltIf, "if"}, lexStatement, nil
+		}
+		if _, ok := ternaryKeywords[strings.ToUpper(name)]; ok {
+			return lexeme{}, nil, l.errorf(stmtExpected())
+		}
+		if sym, ok := keywords[strings.ToUpper(name)]; ok {
+			if sym != negateSym {
+				return lexeme{}, nil, l.errorf(stmtExpected())
+			}
+			l.allowEOF = false
+			return lexeme{ltNegate, string(negateSym)}, lexStatement, nil
+		}
+		return lexeme{ltStatement, name}, lexOperator, nil
 	}
-	return 0, nil, fmt.Errorf("unexpected char '%c'; expected '%c', '(', '0', '1', or a statement", n, negateSym)
+	return lexeme{}, nil, l.errorf(stmtExpected())
 }
 
 // lexOperator is a statefn for parsing a binary operator or a closing parenthesis.
-func lexOperator(n byte, l *lexer) (lexemeType, statefn, error) {
-	switch n {
-	case ')':
+func lexOperator(n rune, l *lexer) (lexeme, statefn, error) {
+	sym := canonicalize(n)
+	switch {
+	case sym == ')':
 		if !l.denest() {
-			return 0, nil, errors.New("unexpected closing parenthesis: no corresponding opening parenthesis")
+			// No corresponding opening parenthesis.
+			return lexeme{}, nil, l.errorf(l.opExpected())
 		}
-		return ltCloseParen, lexOperator, nil
-	case andSym, orSym, xorSym, condSym, bicondSym:
+		return lexeme{ltCloseParen, ")"}, lexOperator, nil
+	case n == andSym:
+		// Also accept "&&".
+		l.accept("&")
+	case n == orSym:
+		// Also accept "||".
+		l.accept("|")
+	case n == '-' && l.accept(">"):
+		sym = condSym
+	case n == '<' && l.accept("->"):
+		sym = bicondSym
+	case isIdentStart(n):
+		name := l.ident()
+		if lt, ok := ternaryKeywords[strings.ToUpper(name)]; ok && lt != ltIf {
+			l.allowEOF = false
+			return lexeme{lt, strings.ToLower(name)}, lexStatement, nil
+		}
+		if kw, ok := keywords[strings.ToUpper(name)]; ok && kw != negateSym {
+			sym = kw
+		} else {
+			return lexeme{}, nil, l.errorf(l.opExpected())
+		}
+	}
+	switch sym {
+	case andSym, orSym, xorSym, condSym, bicondSym, nandSym, norSym, xnorSym:
 		l.allowEOF = false
-		return ltOperator, lexStatement, nil
+		return lexeme{ltOperator, string(sym)}, lexStatement, nil
 	}
-	return 0, nil, fmt.Errorf("unexpected char '%c'; expected ')', '%c', '%c', '%c', '%c', or '%c'",
-		n, andSym, orSym, xorSym, condSym, bicondSym)
+	return lexeme{}, nil, l.errorf(l.opExpected())
 }
@@ -8,45 +8,106 @@ func TestLex(t *testing.T) {
 		expected []lexeme
 	}
 	for _, c := range []testCase{
-		{"a", []lexeme{{ltStatement, 'a'}}},
+		{"a", []lexeme{{ltStatement, "a"}}},
 		{"(a)", []lexeme{
-			{ltOpenParen, '('},
-			{ltStatement, 'a'},
-			{ltCloseParen, ')'},
+			{ltOpenParen, "("},
+			{ltStatement, "a"},
+			{ltCloseParen, ")"},
 		}},
 		{"a > b", []lexeme{
-			{ltStatement, 'a'},
-			{ltOperator, '>'},
-			{ltStatement, 'b'},
+			{ltStatement, "a"},
+			{ltOperator, ">"},
+			{ltStatement, "b"},
 		}},
 		{"  (  a       >b)   &   1 ", []lexeme{
-			{ltOpenParen, '('},
-			{ltStatement, 'a'},
-			{ltOperator, '>'},
-			{ltStatement, 'b'},
-			{ltCloseParen, ')'},
-			{ltOperator, '&'},
-			{ltTrue, '1'},
+			{ltOpenParen, "("},
+			{ltStatement, "a"},
+			{ltOperator, ">"},
+			{ltStatement, "b"},
+			{ltCloseParen, ")"},
+			{ltOperator, "&"},
+			{ltTrue, "1"},
+		}},
+		{"door_open & !alarm_armed", []lexeme{
+			{ltStatement, "door_open"},
+			{ltOperator, "&"},
+			{ltNegate, "!"},
+			{ltStatement, "alarm_armed"},
+		}},
+		{"(_x1|Y2z)", []lexeme{
+			{ltOpenParen, "("},
+			{ltStatement, "_x1"},
+			{ltOperator, "|"},
+			{ltStatement, "Y2z"},
+			{ltCloseParen, ")"},
+		}},
+		{"¬(p ∧ q) ∨ ⊤ → r ↔ ⊥ ⊕ s", []lexeme{
+			{ltNegate, "!"},
+			{ltOpenParen, "("},
+			{ltStatement, "p"},
+			{ltOperator, "&"},
+			{ltStatement, "q"},
+			{ltCloseParen, ")"},
+			{ltOperator, "|"},
+			{ltTrue, "1"},
+			{ltOperator, ">"},
+			{ltStatement, "r"},
+			{ltOperator, "="},
+			{ltFalse, "0"},
+			{ltOperator, "^"},
+			{ltStatement, "s"},
+		}},
+		{"NOT a AND b OR c XOR d IMPLIES e IFF not f", []lexeme{
+			{ltNegate, "!"},
+			{ltStatement, "a"},
+			{ltOperator, "&"},
+			{ltStatement, "b"},
+			{ltOperator, "|"},
+			{ltStatement, "c"},
+			{ltOperator, "^"},
+			{ltStatement, "d"},
+			{ltOperator, ">"},
+			{ltStatement, "e"},
+			{ltOperator, "="},
+			{ltNegate, "!"},
+			{ltStatement, "f"},
+		}},
+		{"~a&&b||c->d<->e", []lexeme{
+			{ltNegate, "!"},
+			{ltStatement, "a"},
+			{ltOperator, "&"},
+			{ltStatement, "b"},
+			{ltOperator, "|"},
+			{ltStatement, "c"},
+			{ltOperator, ">"},
+			{ltStatement, "d"},
+			{ltOperator, "="},
+			{ltStatement, "e"},
+		}},
+		{"android | order", []lexeme{
+			{ltStatement, "android"},
+			{ltOperator, "|"},
+			{ltStatement, "order"},
 		}},
 		{"!(!(a = b) | !0) > (c ^ d)", []lexeme{
-			{ltNegate, '!'},
-			{ltOpenParen, '('},
-			{ltNegate, '!'},
-			{ltOpenParen, '('},
-			{ltStatement, 'a'},
-			{ltOperator, '='},
-			{ltStatement, 'b'},
-			{ltCloseParen, ')'},
-			{ltOperator, '|'},
-			{ltNegate, '!'},
-			{ltFalse, '0'},
-			{ltCloseParen, ')'},
-			{ltOperator, '>'},
-			{ltOpenParen, '('},
-			{ltStatement, 'c'},
-			{ltOperator, '^'},
-			{ltStatement, 'd'},
-			{ltCloseParen, ')'},
+			{ltNegate, "!"},
+			{ltOpenParen, "("},
+			{ltNegate, "!"},
+			{ltOpenParen, "("},
+			{ltStatement, "a"},
+			{ltOperator, "="},
+			{ltStatement, "b"},
+			{ltCloseParen, ")"},
+			{ltOperator, "|"},
+			{ltNegate, "!"},
+			{ltFalse, "0"},
+			{ltCloseParen, ")"},
+			{ltOperator, ">"},
+			{ltOpenParen, "("},
+			{ltStatement, "c"},
+			{ltOperator, "^"},
+			{ltStatement, "d"},
+			{ltCloseParen, ")"},
 		}},
 	} {
 		idx := 0
@@ -79,6 +140,16 @@ func TestLexError(t *testing.T) {
 		{"a >", true},
 		{"^ a", true},
 		{"(a & b > c)", false},
+		{"a b", true},
+		{"1a", true},
+		{"a.b", true},
+		{"AND a", true},
+		{"a NOT b", true},
+		{"a - b", true},
+		{"a <- b", true},
+		{"a &&& b", true},
+		{"a foo b", true},
+		{"a ∧", true},
 	} {
 		err := false
 		for r := range lex(c.input) {
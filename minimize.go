@@ -0,0 +1,284 @@
+package vera
+
+import (
+	"math/bits"
+	"sort"
+	"strings"
+)
+
+// Cube is a product term (a conjunction of possibly negated atomic statements) over the atomic statements of a Truth.
+// Bit i of Mask is set if the atomic statement t.Names[i] appears in the cube, in which case bit i of Val is set if it
+// appears positively and unset if it appears negated. Bits of Val which are not in Mask are always unset.
+type Cube struct {
+	Mask uint64
+	Val  uint64
+}
+
+// Covers reports whether the cube is true at the given set of truth values (i.e. at Truth.Val == val).
+func (c Cube) Covers(val uint64) bool {
+	return val&c.Mask == c.Val
+}
+
+// Literals returns the number of literals in the cube.
+func (c Cube) Literals() int {
+	return bits.OnesCount64(c.Mask)
+}
+
+// Pattern returns the cube in the notation used for Quine-McCluskey tables, with one character per atomic statement in
+// the given Truth (in the same order as the columns of a truth table): '1' if it appears positively, '0' if it appears
+// negated, and '-' if it does not appear.
+func (c Cube) Pattern(t Truth) string {
+	var sb strings.Builder
+	for i := len(t.Names) - 1; i >= 0; i-- {
+		switch {
+		case c.Mask&(1<<i) == 0:
+			sb.WriteByte('-')
+		case c.Val&(1<<i) > 0:
+			sb.WriteByte('1')
+		default:
+			sb.WriteByte('0')
+		}
+	}
+	return sb.String()
+}
+
+// Stmt returns the cube as a conjunction of the atomic statements in the given Truth (in the same order as the columns
+// of a truth table). The empty cube is Const(true).
+func (c Cube) Stmt(t Truth) Stmt {
+	var s Stmt
+	for i := len(t.Names) - 1; i >= 0; i-- {
+		if c.Mask&(1<<i) == 0 {
+			continue
+		}
+		var l Stmt = Var(t.Names[i])
+		if c.Val&(1<<i) == 0 {
+			l = Not{l}
+		}
+		if s == nil {
+			s = l
+		} else {
+			s = Binary{OpAnd, s, l}
+		}
+	}
+	if s == nil {
+		return Const(true)
+	}
+	return s
+}
+
+// Cover is a sum of products: a disjunction of Cubes.
+type Cover []Cube
+
+// Stmt returns the cover as a disjunction of the Stmts of its cubes (see Cube.Stmt). The empty cover is Const(false).
+func (c Cover) Stmt(t Truth) Stmt {
+	var s Stmt
+	for _, cube := range c {
+		if s == nil {
+			s = cube.Stmt(t)
+		} else {
+			s = Binary{OpOr, s, cube.Stmt(t)}
+		}
+	}
+	if s == nil {
+		return Const(false)
+	}
+	return s
+}
+
+// Literals returns the total number of literals in the cover.
+func (c Cover) Literals() int {
+	n := 0
+	for _, cube := range c {
+		n += cube.Literals()
+	}
+	return n
+}
+
+// sort sorts the cubes in the cover in the order of their patterns, with '1' before '0' before '-' in each position,
+// so that covers are printed in a predictable order.
+func (c Cover) sort(t Truth) {
+	sort.Slice(c, func(i, j int) bool {
+		// Conveniently, '1' > '0' > '-' in ASCII.
+		return c[i].Pattern(t) > c[j].Pattern(t)
+	})
+}
+
+// Minimize returns a minimal sum-of-products Stmt which is equivalent to the given Stmt. See MinimalCover.
+func Minimize(s Stmt) Stmt {
+	cover, truth := MinimalCover(s)
+	return cover.Stmt(truth)
+}
+
+// MinimalCover returns a minimal set of prime implicants of the given Stmt whose disjunction is equivalent to it, along
+// with the Truth over which the cubes are defined. Minimal means that no cover has fewer cubes, and no cover with the
+// same number of cubes has fewer literals.
+// The Stmt is evaluated at every set of truth values to find its minterms, which are combined into prime implicants
+// with the Quine-McCluskey method; a minimal cover is then chosen from the essential prime implicants and, for any
+// minterms they do not cover, Petrick's method. Since this is exponential in the number of atomic statements, it is
+// only practical for Stmts with up to about 12-15 atomic statements; see HeuristicCover for wider Stmts. MinimalCover
+// panics if the Stmt has more than 32 atomic statements (see NewTruthTable).
+func MinimalCover(s Stmt) (Cover, Truth) {
+	truth := TruthFor(s)
+	table, err := NewTruthTable(s, truth)
+	if err != nil {
+		panic("vera.MinimalCover: " + err.Error())
+	}
+	var minterms []uint64
+	for row := uint64(0); row < table.Rows(); row++ {
+		if table.Get(row) {
+			minterms = append(minterms, row)
+		}
+	}
+	primes := primeImplicants(minterms, len(truth.Names))
+	cover := selectCover(primes, minterms)
+	cover.sort(truth)
+	return cover, truth
+}
+
+// primeImplicants returns the prime implicants of the function of n variables with the given minterms, using the
+// Quine-McCluskey method: starting from the minterms, pairs of cubes which differ in only one variable are repeatedly
+// combined into a cube without that variable, and the cubes which cannot be combined any further are prime.
+func primeImplicants(minterms []uint64, n int) Cover {
+	full := uint64(1)<<n - 1
+	level := make(map[Cube]bool, len(minterms))
+	for _, m := range minterms {
+		level[Cube{full, m}] = false
+	}
+	var primes Cover
+	for len(level) > 0 {
+		next := make(map[Cube]bool)
+		for c := range level {
+			// Look for a partner which differs only by having bit b set; each pair is found once, from the cube with
+			// the bit unset.
+			for rest := c.Mask &^ c.Val; rest != 0; rest &= rest - 1 {
+				b := rest & -rest
+				partner := Cube{c.Mask, c.Val | b}
+				if _, ok := level[partner]; ok {
+					level[c] = true
+					level[partner] = true
+					next[Cube{c.Mask &^ b, c.Val}] = false
+				}
+			}
+		}
+		for c, combined := range level {
+			if !combined {
+				primes = append(primes, c)
+			}
+		}
+		level = next
+	}
+	return primes
+}
+
+// selectCover chooses a minimal subset of the given prime implicants which covers every one of the given minterms.
+func selectCover(primes Cover, minterms []uint64) Cover {
+	// Sort the primes so the result is deterministic despite primeImplicants using maps.
+	sort.Slice(primes, func(i, j int) bool {
+		return primes[i].Mask < primes[j].Mask || (primes[i].Mask == primes[j].Mask && primes[i].Val < primes[j].Val)
+	})
+	// coveredBy[i] is the set of indices of primes covering minterms[i].
+	coveredBy := make([][]int, len(minterms))
+	for i, m := range minterms {
+		for j, p := range primes {
+			if p.Covers(m) {
+				coveredBy[i] = append(coveredBy[i], j)
+			}
+		}
+	}
+	// A prime is essential if it is the only one covering some minterm.
+	chosen := make(map[int]bool)
+	for _, ps := range coveredBy {
+		if len(ps) == 1 {
+			chosen[ps[0]] = true
+		}
+	}
+	// Petrick's method: the remaining minterms give a product of sums of primes (each minterm must be covered by one of
+	// the primes covering it), which is multiplied out into a sum of products; each product is a set of primes
+	// forming a cover, and the cheapest is chosen.
+	products := []BitSet{nil}
+	for _, ps := range coveredBy {
+		covered := false
+		for _, p := range ps {
+			if chosen[p] {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			products = multiplySum(products, ps)
+		}
+	}
+	best := products[0]
+	bestCost := coverCost(primes, best)
+	for _, prod := range products[1:] {
+		if cost := coverCost(primes, prod); cost.less(bestCost) {
+			best, bestCost = prod, cost
+		}
+	}
+	var cover Cover
+	for j, p := range primes {
+		if chosen[j] || best.Get(j) {
+			cover = append(cover, p)
+		}
+	}
+	return cover
+}
+
+// multiplySum multiplies a sum of products of primes by a sum of primes, applying the absorption law (X + XY = X) to
+// keep the result small. Each product is a set of indices of primes.
+func multiplySum(products []BitSet, sum []int) []BitSet {
+	var result []BitSet
+	for _, prod := range products {
+		// X(X + Y) = X, so a product already containing one of the primes in the sum is unchanged.
+		contains := false
+		for _, p := range sum {
+			if prod.Get(p) {
+				contains = true
+				break
+			}
+		}
+		if contains {
+			result = append(result, prod)
+			continue
+		}
+		for _, p := range sum {
+			grown := prod.Clone()
+			grown.Set(p, true)
+			result = append(result, grown)
+		}
+	}
+	// Remove products which are supersets of other products.
+	absorbed := result[:0]
+outer:
+	for i, prod := range result {
+		for j, other := range result {
+			if i != j && other.subsetOf(prod) && (!prod.subsetOf(other) || j < i) {
+				continue outer
+			}
+		}
+		absorbed = append(absorbed, prod)
+	}
+	return absorbed
+}
+
+// cost is the cost of a cover: first the number of cubes, then the number of literals.
+type cost struct {
+	cubes    int
+	literals int
+}
+
+func (c cost) less(o cost) bool {
+	return c.cubes < o.cubes || (c.cubes == o.cubes && c.literals < o.literals)
+}
+
+// coverCost returns the cost of the set of primes with the given indices.
+func coverCost(primes Cover, set BitSet) cost {
+	var c cost
+	for j, p := range primes {
+		if set.Get(j) {
+			c.cubes++
+			c.literals += p.Literals()
+		}
+	}
+	return c
+}
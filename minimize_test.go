@@ -0,0 +1,80 @@
+package vera
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestMinimize(t *testing.T) {
+	type testCase struct {
+		input    string
+		expected string
+	}
+	for _, c := range []testCase{
+		{"(a & b) | (a & !b)", "a"},
+		{"(a & b) | (!a & c) | (b & c)", "(a & b) | (!a & c)"},
+		{"a | !a", "1"},
+		{"a & !a", "0"},
+		{"a ^ b", "(a & !b) | (!a & b)"},
+		{"a > b", "!a | b"},
+		{"if c then a else b", "(a & c) | (b & !c)"},
+	} {
+		s, _, err := ParseWithOptions(c.input, Options{Precedence: Standard})
+		if err != nil {
+			t.Fatalf("error occurred while parsing: %v (input: %s)", err, c.input)
+		}
+		if actual := Minimize(s).String(); actual != c.expected {
+			t.Fatalf("expected '%s' for minimization of '%s'; got '%s'", c.expected, c.input, actual)
+		}
+	}
+}
+
+func TestMinimalCoverCyclic(t *testing.T) {
+	// Minterms 0, 1, 2, 5, 6, 7 of a, b, c: every minterm is covered by two of the six prime implicants, so there are
+	// no essential prime implicants and Petrick's method must pick one of the two minimal covers.
+	s, _, err := ParseWithOptions("(!a & !b) | (!a & !c) | (a & c) | (a & b) | (!b & c) | (b & !c)",
+		Options{Precedence: Standard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cover, truth := MinimalCover(s)
+	if len(cover) != 3 || cover.Literals() != 6 {
+		t.Fatalf("expected a cover of 3 cubes and 6 literals; got %s", cover.Stmt(truth))
+	}
+	if equiv, _ := Equivalent(s, cover.Stmt(truth)); !equiv {
+		t.Fatalf("expected cover %s to be equivalent to %s", cover.Stmt(truth), s)
+	}
+}
+
+func TestMinimizeRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 500; i++ {
+		s := randomStmt(r, 5)
+		cover, truth := MinimalCover(s)
+		if equiv, _ := Equivalent(s, cover.Stmt(truth)); !equiv {
+			t.Fatalf("expected cover %s to be equivalent to %s", cover.Stmt(truth), s)
+		}
+		for _, cube := range cover {
+			// Each cube must be a prime implicant: it implies s, but no cube with one fewer literal does.
+			if !implies(cube, s, truth) {
+				t.Fatalf("cube %s does not imply %s", cube.Pattern(truth), s)
+			}
+			for rest := cube.Mask; rest != 0; rest &= rest - 1 {
+				b := rest & -rest
+				if implies(Cube{cube.Mask &^ b, cube.Val &^ b}, s, truth) {
+					t.Fatalf("cube %s is not a prime implicant of %s", cube.Pattern(truth), s)
+				}
+			}
+		}
+	}
+}
+
+// implies returns whether s is true at every set of truth values covered by the cube.
+func implies(c Cube, s Stmt, truth Truth) bool {
+	for truth.Val = 0; truth.Val < 1<<len(truth.Names); truth.Val++ {
+		if c.Covers(truth.Val) && !s.Eval(truth) {
+			return false
+		}
+	}
+	return true
+}
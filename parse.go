@@ -3,61 +3,93 @@ package vera
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 )
 
-// alphaToIdx maps the given byte corresponding to an English letter to the range [0, 51] (e.g 'A' is mapped to 0 and
-// 'z' is mapped to 51).
-func alphaToIdx(ascii byte) byte {
-	if ascii >= 'a' {
-		ascii -= 6
-	}
-	return ascii - 65
-}
-
-// idxToAlpha does the reverse of alphaToIdx.
-func idxToAlpha(idx byte) byte {
-	if idx >= 26 {
-		idx += 6
-	}
-	return idx + 65
-}
-
 // Truth represents a set of truth values.
 // The truth values are represented by Val, which is treated like a bit field where each bit represents whether the
-// corresponding atomic statement is true (1) or false (0). The bits are in alphabetical order such that, if all 52
-// atomic statements are used, the 0th bit corresponds to 'z' and the 51st bit corresponds to 'A'. However, if some of
-// the 52 possible atomic statements are not used, they will not be included in the bit field (e.g if only 'a' and 'G'
-// are used, the 0th bit will correspond to 'a' and the 1st bit will correspond to 'G'; the remaining bits are
-// meaningless).
+// corresponding atomic statement is true (1) or false (0). The bits are assigned in reverse lexicographic (byte-wise)
+// order of the names of the atomic statements that appear in the parsed input, such that the 0th bit corresponds to the
+// name that sorts last and the highest used bit corresponds to the name that sorts first (e.g. if only "a", "G", and
+// "door_open" are used, the 0th bit will correspond to "door_open", the 1st bit will correspond to "a", and the 2nd bit
+// will correspond to "G"; the remaining bits are meaningless).
 // As a result of the truth values being represented as a uint64, it is very easy to iterate over all possible truth
 // values for a statement; for example:
-// 		stmt, t, err := vera.Parse(...)
-//		// check err
-// 		for t.Val = 0; t.Val < 1 << len(t.Names); t.Val++ {
-//			// Do something with t such as call stmt.Eval.
-//		}
+//
+//	stmt, t, err := vera.Parse(...)
+//	// check err
+//	for t.Val = 0; t.Val < 1 << len(t.Names); t.Val++ {
+//		// Do something with t such as call stmt.Eval.
+//	}
+//
 // If the names associated with each bit value are needed, they are stored in the Names slice which uses the same
 // indexing scheme as the bits in Val (e.g. t.Val&(1<<i)>0 accesses the value of the statement named t.Names[i] for some
 // Truth t and integer i < len(t.Names)).
+// If there are more than 64 atomic statements, Val is not wide enough and is ignored; the truth values are instead
+// stored in Wide using the same indexing scheme (e.g. t.Wide.Get(i) accesses the value of t.Names[i]). Get and Set may
+// be used to access a truth value by name regardless of which representation is in use. Note that copies of a Truth
+// share the same Wide, so use Clone before modifying a copy that must remain independent.
 type Truth struct {
 	Val      uint64
-	shiftMap *[52]byte
-	Names    []byte
+	Wide     BitSet
+	shiftMap map[string]int
+	Names    []string
+}
+
+// isWide returns whether the truth values are stored in Wide rather than Val.
+func (t Truth) isWide() bool {
+	return len(t.Names) > 64
 }
 
 // get returns the value of the given atomic statement for this set of truth values.
-func (t Truth) get(stmt byte) bool {
-	return t.Val&(1<<t.shiftMap[alphaToIdx(stmt)]) > 0
+func (t Truth) get(stmt string) bool {
+	if t.isWide() {
+		return t.Wide.Get(t.shiftMap[stmt])
+	}
+	return t.Val&(1<<t.shiftMap[stmt]) > 0
+}
+
+// Get returns the value of the atomic statement with the given name. The boolean return value indicates whether the
+// name is one of the atomic statements in t.Names; if it is false, the first return value should be disregarded.
+func (t Truth) Get(name string) (bool, bool) {
+	if _, ok := t.shiftMap[name]; !ok {
+		return false, false
+	}
+	return t.get(name), true
+}
+
+// Set sets the value of the atomic statement with the given name. False is returned if the name is not one of the
+// atomic statements in t.Names, in which case t is left unchanged.
+func (t *Truth) Set(name string, val bool) bool {
+	i, ok := t.shiftMap[name]
+	if !ok {
+		return false
+	}
+	switch {
+	case t.isWide():
+		t.Wide.Set(i, val)
+	case val:
+		t.Val |= 1 << i
+	default:
+		t.Val &^= 1 << i
+	}
+	return true
+}
+
+// Clone returns a copy of t whose truth values may be modified without affecting t.
+func (t Truth) Clone() Truth {
+	t.Wide = t.Wide.Clone()
+	return t
 }
 
 func (t Truth) String() string {
 	var sb strings.Builder
 	sb.WriteByte('{')
-	for i, b := range t.Names {
-		sb.WriteByte(b)
+	for i, name := range t.Names {
+		sb.WriteString(name)
 		sb.WriteByte(':')
-		if t.get(b) {
+		if t.get(name) {
 			sb.WriteByte('1')
 		} else {
 			sb.WriteByte('0')
@@ -70,250 +102,310 @@ func (t Truth) String() string {
 	return sb.String()
 }
 
-func newTruth(atomics uint64) Truth {
-	var shiftMap [52]byte
-	names := make([]byte, 0, 52)
-	var shift byte
-	// Here we count down from 51 instead of up from 0 to effectively reverse the bit order in Truth.Val.
-	// This allows us to display each atomic in alphabetical order in a truth table and not have the rows "appear
-	// backwards" (e.g. {0-0, 1-0, 0-1, 1-1} instead of {0-0, 0-1, 1-0, 1-1}) yet still be able to count up by simply
-	// incrementing Truth.Val.
-	// Less than 52 in condition because of wrap around.
-	for i := byte(51); i < 52; i-- {
-		if atomics&(1<<i) > 0 {
-			shiftMap[i] = shift
-			shift++
-			// Use append even though we pre-allocated so that the slice length represents the number of atomics.
-			names = append(names, idxToAlpha(i))
-		}
-	}
-	return Truth{0, &shiftMap, names}
+// TruthFor creates a Truth for the union of the atomic statements appearing in the given Stmts, with every truth value
+// set to false. It is useful for Stmts which were not returned by Parse (e.g. Stmts built by hand or by Rewrite).
+func TruthFor(stmts ...Stmt) Truth {
+	return newTruth(atomicsOf(stmts...))
 }
 
-// operator represents a binary logical operator.
-type operator func(bool, bool) bool
-
-type Stmt interface {
-	fmt.Stringer
-	Eval(Truth) bool
-}
-
-// surroundIfBinary returns the string representation of the given Stmt and surrounds it in parentheses if it is a
-// binaryStmt.
-func surroundIfBinary(s Stmt) string {
-	if _, ok := s.(binaryStmt); ok {
-		return "(" + s.String() + ")"
+// atomicsOf returns the set of names of the atomic statements appearing in the given Stmts.
+func atomicsOf(stmts ...Stmt) map[string]struct{} {
+	atomics := make(map[string]struct{})
+	for _, stmt := range stmts {
+		Walk(stmt, func(s Stmt) bool {
+			if v, ok := s.(Var); ok {
+				atomics[string(v)] = struct{}{}
+			}
+			return true
+		})
 	}
-	return s.String()
-}
-
-type falseStmt struct{}
-
-func (falseStmt) Eval(Truth) bool {
-	return false
-}
-
-func (falseStmt) String() string {
-	return "0"
+	return atomics
 }
 
-type trueStmt struct{}
-
-func (trueStmt) Eval(Truth) bool {
-	return true
-}
-
-func (trueStmt) String() string {
-	return "1"
-}
-
-type negatedStmt struct {
-	Stmt
+// newTruth creates a Truth for the given set of atomic statement names.
+func newTruth(atomics map[string]struct{}) Truth {
+	names := make([]string, 0, len(atomics))
+	for name := range atomics {
+		names = append(names, name)
+	}
+	// Here we sort in descending order to effectively reverse the bit order in Truth.Val.
+	// This allows us to display each atomic in lexicographic order in a truth table and not have the rows "appear
+	// backwards" (e.g. {0-0, 1-0, 0-1, 1-1} instead of {0-0, 0-1, 1-0, 1-1}) yet still be able to count up by simply
+	// incrementing Truth.Val.
+	sort.Sort(sort.Reverse(sort.StringSlice(names)))
+	shiftMap := make(map[string]int, len(names))
+	for i, name := range names {
+		shiftMap[name] = i
+	}
+	var wide BitSet
+	if len(names) > 64 {
+		wide = make(BitSet, (len(names)+63)/64)
+	}
+	return Truth{0, wide, shiftMap, names}
 }
 
-func (s negatedStmt) Eval(t Truth) bool {
-	return !s.Stmt.Eval(t)
-}
+// Precedence determines how chains of binary operators which are not parenthesized are grouped when parsing.
+type Precedence byte
+
+const (
+	// Strict assigns all binary operators equal precedence, so any chain of binary operators must be parenthesized
+	// (e.g. "a & b & c" is rejected; "(a & b) & c" must be used instead). This is the default.
+	Strict Precedence = iota
+	// Standard uses the conventional precedence, from highest to lowest: '!', '&', '^', '|', '>', '='. '&', '|', '^',
+	// and '=' are left-associative, and '>' is right-associative (e.g. "a > b > c" is parsed as "a > (b > c)").
+	// NAND ('↑'), XNOR ('⊙'), and NOR ('↓') share the precedence of AND, XOR, and OR respectively and are
+	// left-associative.
+	Standard
+)
 
-func (s negatedStmt) String() string {
-	return "!" + surroundIfBinary(s.Stmt)
+// Options configures ParseWithOptions. The zero value is the configuration used by Parse.
+type Options struct {
+	Precedence Precedence
 }
 
-type atomicStmt byte
-
-func (s atomicStmt) Eval(t Truth) bool {
-	return t.get(byte(s))
+// Parse parses the given input string, returning a Stmt which can then be evaluated at certain sets of truth values
+// using the given Truth. An error is also returned in the case of failure.
+// Parse uses the Strict precedence mode; see ParseWithOptions to use another mode.
+func Parse(input string) (Stmt, Truth, error) {
+	return ParseWithOptions(input, Options{})
 }
 
-func (s atomicStmt) String() string {
-	return string(s)
+// ParseWithOptions is like Parse, but allows the parsing behaviour to be configured with the given Options.
+// Syntax errors are returned as a *ParseError.
+func ParseWithOptions(input string, opts Options) (Stmt, Truth, error) {
+	p := &parser{
+		input:   input,
+		c:       lex(input),
+		opts:    opts,
+		atomics: make(map[string]struct{}),
+		terms:   []string{"EOF"},
+	}
+	stmt, err := p.parse()
+	if err != nil {
+		// The parser may stop before the lexer does, which would leave the lexer blocked sending the rest of the input
+		// forever, so drain the channel to let it finish.
+		for range p.c {
+		}
+	}
+	return stmt, newTruth(p.atomics), err
 }
 
-type binaryStmt struct {
-	left  Stmt
-	op    operator
-	right Stmt
-	opSym string
+// stmtBuilder is used internally inside parseUnary to manage negations.
+type stmtBuilder struct {
+	inner   Stmt
+	negated bool
 }
 
-func (s binaryStmt) Eval(t Truth) bool {
-	return s.op(s.left.Eval(t), s.right.Eval(t))
+func (sb *stmtBuilder) negate() {
+	sb.negated = !sb.negated
 }
 
-func (s binaryStmt) String() string {
-	return surroundIfBinary(s.left) + s.opSym + surroundIfBinary(s.right)
+func (sb *stmtBuilder) build() Stmt {
+	if sb.negated {
+		return Not{sb.inner}
+	}
+	return sb.inner
 }
 
-func and(left bool, right bool) bool {
-	return left && right
+// parser is a precedence climbing parser over the lexemes produced by a lexer.
+type parser struct {
+	input string
+	c     chan lexerResult
+	opts  Options
+	// atomics is the set of names of all atomic statements which appeared in the input.
+	atomics map[string]struct{}
+	// peeked holds the lexerResult returned by the last call to peek, if it has not yet been consumed by next.
+	peeked *lexerResult
+	// terms is a stack of the tokens which may terminate the expression currently being parsed (e.g. "')'" inside
+	// parentheses or "'then'" in the condition of a ternary conditional expression); it is used to report what was
+	// expected in errors.
+	terms []string
 }
 
-func or(left bool, right bool) bool {
-	return left || right
+// next consumes and returns the next lexeme. The boolean return value indicates if the end of the input was reached
+// (i.e. EOF); if it is true, the lexeme return value should be disregarded.
+func (p *parser) next() (lexeme, bool, error) {
+	l, eof, err := p.peek()
+	p.peeked = nil
+	return l, eof, err
 }
 
-func xor(left bool, right bool) bool {
-	return (left && !right) || (!left && right)
+// peek returns the next lexeme without consuming it. The return values have the same meaning as for next.
+func (p *parser) peek() (lexeme, bool, error) {
+	if p.peeked == nil {
+		lr, ok := <-p.c
+		if !ok {
+			// Closing the channel without any errors implies EOF.
+			return lexeme{}, true, nil
+		}
+		p.peeked = &lr
+	}
+	return p.peeked.l, false, p.peeked.err
 }
 
-func cond(left bool, right bool) bool {
-	return !left || right
+// errorf returns a *ParseError for the peeked lexeme (or EOF, if nothing is peeked), which was found when the
+// terminator at the top of p.terms was expected.
+func (p *parser) errorf() *ParseError {
+	expected := []string{p.terms[len(p.terms)-1]}
+	if p.peeked == nil {
+		return newParseError(p.input, len(p.input), "EOF", expected)
+	}
+	found := quoteToken(p.input[p.peeked.start:p.peeked.end])
+	return newParseError(p.input, p.peeked.start, found, expected)
 }
 
-func bicond(left bool, right bool) bool {
-	return left == right
+// parseTerminated parses an expression which must be followed by a lexeme of the given type, consuming that lexeme.
+// term is the form of the lexeme used in errors.
+func (p *parser) parseTerminated(lt lexemeType, term string) (Stmt, error) {
+	p.terms = append(p.terms, term)
+	stmt, err := p.parseExpr(0)
+	if err != nil {
+		return nil, err
+	}
+	if l, eof, err := p.peek(); err != nil {
+		return nil, err
+	} else if eof || l.t != lt {
+		return nil, p.errorf()
+	}
+	_, _, _ = p.next()
+	p.terms = p.terms[:len(p.terms)-1]
+	return stmt, nil
 }
 
-// byteToOp takes an operator symbol in the form of a byte and returns the associated operator function.
-func byteToOp(b byte) operator {
-	switch b {
-	case andSym:
-		return and
-	case orSym:
-		return or
-	case xorSym:
-		return xor
-	case condSym:
-		return cond
-	case bicondSym:
-		return bicond
+// precedence returns the binding power of the given binary operator symbol (higher binds tighter) and whether it is
+// right-associative.
+func (p *parser) precedence(sym string) (int, bool) {
+	if p.opts.Precedence == Strict {
+		return 1, false
+	}
+	switch sym {
+	case string(andSym), string(nandSym):
+		return 5, false
+	case string(xorSym), string(xnorSym):
+		return 4, false
+	case string(orSym), string(norSym):
+		return 3, false
+	case string(condSym):
+		return 2, true
+	case string(bicondSym):
+		return 1, false
 	default:
 		// Lexer should guarantee this never happens.
-		panic(fmt.Sprintf("invalid op byte '%c'", b))
+		panic(fmt.Sprintf("invalid op symbol '%s'", sym))
 	}
 }
 
-// Parse parses the given input string, returning a Stmt which can then be evaluated at certain sets of truth values
-// using the given Truth. An error is also returned in the case of failure.
-func Parse(input string) (Stmt, Truth, error) {
-	stmt, atomics, err := parseRecursive(lex(input))
-	return stmt, newTruth(atomics), err
-}
-
-// stmtBuilder is used internally inside parseRecursive to manage negations.
-type stmtBuilder struct {
-	inner   Stmt
-	negated bool
+// parse parses the entire input.
+func (p *parser) parse() (Stmt, error) {
+	stmt, err := p.parseExpr(0)
+	if err != nil {
+		return nil, err
+	}
+	if _, eof, err := p.peek(); err != nil {
+		return nil, err
+	} else if !eof {
+		// Unmatched closing parentheses are lexer errors, so this can only be a stray "then" or "else".
+		return nil, p.errorf()
+	}
+	return stmt, nil
 }
 
-func (sb *stmtBuilder) negate() {
-	sb.negated = !sb.negated
-}
+// strictChainReason is the ParseError.Reason for a chain of binary operators without parentheses in Strict mode.
+const strictChainReason = "binary operators must be parenthesized in strict precedence mode (or use standard precedence)"
 
-func (sb *stmtBuilder) build() Stmt {
-	if sb.negated {
-		return negatedStmt{sb.inner}
+// parseExpr parses a sequence of statements joined by binary operators with a precedence of at least minPrec.
+func (p *parser) parseExpr(minPrec int) (Stmt, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		l, eof, err := p.peek()
+		if err != nil {
+			return nil, err
+		}
+		if eof || l.t != ltOperator {
+			return left, nil
+		}
+		prec, rightAssoc := p.precedence(l.v)
+		if prec < minPrec {
+			return left, nil
+		}
+		_, _, _ = p.next()
+		// A right-associative operator lets the right-hand side contain operators of the same precedence.
+		nextMin := prec + 1
+		if rightAssoc {
+			nextMin = prec
+		}
+		right, err := p.parseExpr(nextMin)
+		if err != nil {
+			return nil, err
+		}
+		left = Binary{symToOp(l.v), left, right}
+		if p.opts.Precedence == Strict {
+			if l, eof, err := p.peek(); err != nil {
+				return nil, err
+			} else if !eof && l.t == ltOperator {
+				// The lexer does not understand that multiple operators chained together without parentheses is
+				// ambiguous, so we must check for it here.
+				err := p.errorf()
+				err.Reason = strictChainReason
+				return nil, err
+			}
+		}
 	}
-	return sb.inner
 }
 
-func parseRecursive(c chan lexerResult) (Stmt, uint64, error) {
-	const (
-		expStmt = iota
-		expOpOrClose
-		expClose
-	)
-	state := expStmt
-	left := &stmtBuilder{}
-	right := &stmtBuilder{}
-	// op contains the binary operator if this invocation of parseRecursive is parsing a binary statement. If this
-	// invocation of parseRecursive is parsing a single statement, op is nil. For example, for the input 'a & (b)', the
-	// op in the outer invocation of parseRecursive will be set to the AND operator, and the op in the inner invocation
-	// of parseRecursive (i.e. when parsing '(b)') will be nil.
-	var op operator
-	var opSym string
-	// atomics is a bit field where a set bit indicates that the corresponding atomic statement (i.e. an ascii letter)
-	// appeared in the input. Whether 'A' occurred in the input is 'atomics & 1', and whether 'z' occurred in the input
-	// is 'atomics & 1 << 51', for example.
-	var atomics uint64
-	// pick is to prevent cluttering below with nil checks on op.
-	pick := func() *stmtBuilder {
-		// "pick" the left or right statement.
-		if op == nil {
-			return left
+// parseUnary parses a single, possibly negated, statement: a constant, an atomic statement, or a parenthesized
+// expression.
+func (p *parser) parseUnary() (Stmt, error) {
+	sb := &stmtBuilder{}
+	for {
+		l, eof, err := p.next()
+		if err != nil {
+			return nil, err
 		}
-		return right
-	}
-forLoop:
-	for lr := range c {
-		if lr.err != nil {
-			return nil, 0, lr.err
+		if eof {
+			// Lexer should guarantee this never happens, since it reports an unexpected EOF as an error.
+			panic("unexpected EOF")
 		}
-		switch state {
-		case expStmt:
-			switch lr.l.t {
-			case ltFalse:
-				pick().inner = falseStmt{}
-			case ltTrue:
-				pick().inner = trueStmt{}
-			case ltNegate:
-				// TODO: try to preserve original statement as faithfully as possible: increment negate counter instead?
-				pick().negate()
-				// continue so state is not set below the switch statement.
-				continue
-			case ltOpenParen:
-				var err error
-				var a uint64
-				pick().inner, a, err = parseRecursive(c)
-				if err != nil {
-					return nil, 0, err
-				}
-				atomics |= a
-			case ltStatement:
-				atomics |= 1 << alphaToIdx(lr.l.v)
-				pick().inner = atomicStmt(lr.l.v)
-			default:
-				// Lexer should guarantee this never happens.
-				panic(fmt.Sprintf("expected False, True, Negate, OpenParen, or Statement, not %s", lr.l.t))
+		switch l.t {
+		case ltFalse:
+			sb.inner = Const(false)
+		case ltTrue:
+			sb.inner = Const(true)
+		case ltNegate:
+			// TODO: try to preserve original statement as faithfully as possible: increment negate counter instead?
+			sb.negate()
+			continue
+		case ltOpenParen:
+			inner, err := p.parseTerminated(ltCloseParen, "')'")
+			if err != nil {
+				return nil, err
 			}
-			if op == nil {
-				state = expOpOrClose
-			} else {
-				state = expClose
+			sb.inner = inner
+		case ltIf:
+			cond, err := p.parseTerminated(ltThen, "'then'")
+			if err != nil {
+				return nil, err
 			}
-		case expOpOrClose:
-			switch lr.l.t {
-			case ltOperator:
-				op = byteToOp(lr.l.v)
-				opSym = " " + string(lr.l.v) + " "
-				state = expStmt
-			case ltCloseParen:
-				break forLoop
-			default:
-				// Lexer should guarantee this never happens.
-				panic(fmt.Sprintf("expected Operator or CloseParen/EOF, not %s", lr.l.t))
+			then, err := p.parseTerminated(ltElse, "'else'")
+			if err != nil {
+				return nil, err
 			}
-		case expClose:
-			if lr.l.t != ltCloseParen {
-				// This should only ever happen if the lexeme is of type ltOperator, since the lexer does not understand
-				// that multiple operators chained together without parentheses is ambiguous.
-				return nil, 0, fmt.Errorf("expected CloseParen/EOF, not %s", lr.l.t)
+			// The else branch extends as far as possible, so it ends wherever the enclosing expression does.
+			els, err := p.parseExpr(0)
+			if err != nil {
+				return nil, err
 			}
-			break forLoop
+			sb.inner = IfThenElse{cond, then, els}
+		case ltStatement:
+			p.atomics[l.v] = struct{}{}
+			sb.inner = Var(l.v)
+		default:
+			// Lexer should guarantee this never happens.
+			panic(fmt.Sprintf("expected False, True, Negate, OpenParen, Statement, or If, not %s", l.t))
 		}
+		return sb.build(), nil
 	}
-	if op == nil {
-		return left.build(), atomics, nil
-	}
-	return binaryStmt{left.build(), op, right.build(), opSym}, atomics, nil
 }
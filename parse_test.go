@@ -1,7 +1,11 @@
 package vera
 
 import (
+	"fmt"
+	"runtime"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestParseEval(t *testing.T) {
@@ -24,6 +28,18 @@ func TestParseEval(t *testing.T) {
 		{"!(a > b)", []bool{false, false, true, false}},
 		{"!!(a = b)", []bool{true, false, false, true}},
 		{"(a = b) | b", []bool{true, true, false, true}},
+		{"door_open & !alarm_armed", []bool{false, true, false, false}},
+		{"a_1 > a_2", []bool{true, true, false, true}},
+		{"a ↑ b", []bool{true, true, true, false}},
+		{"a NAND b", []bool{true, true, true, false}},
+		{"a ↓ b", []bool{true, false, false, false}},
+		{"a nor b", []bool{true, false, false, false}},
+		{"a ⊙ b", []bool{true, false, false, true}},
+		{"a XNOR b", []bool{true, false, false, true}},
+		// Rows are ordered with c as the least significant bit: (a, b, c) = 000, 001, ..., 111.
+		{"if a then b else c", []bool{false, true, false, true, false, false, true, true}},
+		{"IF a THEN 0 ELSE !a", []bool{true, false}},
+		{"!(if a then b else c) & c", []bool{false, false, false, false, false, true, false, false}},
 	} {
 		stmt, truth, err := Parse(c.input)
 		if err != nil {
@@ -52,6 +68,14 @@ func TestParseStmtToString(t *testing.T) {
 		{"a&b", "a & b"},
 		{"(a&b)>c", "(a & b) > c"},
 		{"(a&!0)>!!1", "(a & !0) > 1"},
+		{"door_open&!alarm_armed", "door_open & !alarm_armed"},
+		{"a NAND (b NOR c)", "a ↑ (b ↓ c)"},
+		{"a xnor b", "a ⊙ b"},
+		{"if a then b else c", "if a then b else c"},
+		{"if (a & b) then !c else (if c then 0 else 1)", "if a & b then !c else if c then 0 else 1"},
+		{"!(if a then b else c)", "!(if a then b else c)"},
+		{"(if a then b else c) > d", "(if a then b else c) > d"},
+		{"d > if a then b else c", "d > (if a then b else c)"},
 	} {
 		stmt, _, err := Parse(c.input)
 		if err != nil {
@@ -63,3 +87,155 @@ func TestParseStmtToString(t *testing.T) {
 		}
 	}
 }
+
+func TestTruthNames(t *testing.T) {
+	type testCase struct {
+		input    string
+		expected []string
+	}
+	for _, c := range []testCase{
+		{"a", []string{"a"}},
+		{"b & a", []string{"b", "a"}},
+		{"(a | G) > door_open", []string{"door_open", "a", "G"}},
+		{"x & (x | x1)", []string{"x1", "x"}},
+	} {
+		_, truth, err := Parse(c.input)
+		if err != nil {
+			t.Fatalf("error occurred while parsing: %v (input: %s)", err, c.input)
+		}
+		if len(truth.Names) != len(c.expected) {
+			t.Fatalf("expected names %v; got %v (input: %s)", c.expected, truth.Names, c.input)
+		}
+		for i := range c.expected {
+			if truth.Names[i] != c.expected[i] {
+				t.Fatalf("expected names %v; got %v (input: %s)", c.expected, truth.Names, c.input)
+			}
+		}
+	}
+}
+
+func TestParseEvalWide(t *testing.T) {
+	// Build a conjunction of 150 atomics: (((x0 & x1) & x2) & ...).
+	input := "x0"
+	for i := 1; i < 150; i++ {
+		input = fmt.Sprintf("(%s) & x%d", input, i)
+	}
+	stmt, truth, err := Parse(input)
+	if err != nil {
+		t.Fatalf("error occurred while parsing: %v", err)
+	}
+	if len(truth.Names) != 150 {
+		t.Fatalf("expected 150 names; got %d", len(truth.Names))
+	}
+	for _, name := range truth.Names {
+		if !truth.Set(name, true) {
+			t.Fatalf("failed to set %s", name)
+		}
+	}
+	if !stmt.Eval(truth) {
+		t.Fatal("expected conjunction to be true when all atomics are true")
+	}
+	truth.Set("x149", false)
+	if v, ok := truth.Get("x149"); !ok || v {
+		t.Fatalf("expected x149 to be false; got %t, %t", v, ok)
+	}
+	if stmt.Eval(truth) {
+		t.Fatal("expected conjunction to be false when x149 is false")
+	}
+	if truth.Set("y", true) {
+		t.Fatal("expected Set of unknown atomic to fail")
+	}
+}
+
+func TestTruthSetNarrow(t *testing.T) {
+	stmt, truth, err := Parse("a > b")
+	if err != nil {
+		t.Fatalf("error occurred while parsing: %v", err)
+	}
+	truth.Set("a", true)
+	if stmt.Eval(truth) {
+		t.Fatalf("expected false at %s", truth)
+	}
+	truth.Set("b", true)
+	if !stmt.Eval(truth) {
+		t.Fatalf("expected true at %s", truth)
+	}
+	if truth.Val != 3 {
+		t.Fatalf("expected Val to be 3; got %d", truth.Val)
+	}
+}
+
+func TestParsePrecedence(t *testing.T) {
+	type testCase struct {
+		input    string
+		expected string
+	}
+	for _, c := range []testCase{
+		{"a & b & c", "(a & b) & c"},
+		{"a | b & c", "a | (b & c)"},
+		{"a & b | c", "(a & b) | c"},
+		{"a ^ b | c ^ d", "(a ^ b) | (c ^ d)"},
+		{"a & b ^ c", "(a & b) ^ c"},
+		{"a > b > c", "a > (b > c)"},
+		{"a = b = c", "(a = b) = c"},
+		{"a | b > c = d", "((a | b) > c) = d"},
+		{"!a & b", "!a & b"},
+		{"!(a | b) & c", "!(a | b) & c"},
+		{"a & (b | c)", "a & (b | c)"},
+		{"p & q > r | !s = t", "((p & q) > (r | !s)) = t"},
+		{"a ↑ b ↓ c ⊙ d", "(a ↑ b) ↓ (c ⊙ d)"},
+		{"if a then b else c | d", "if a then b else c | d"},
+		{"if a | b then c & d else e", "if a | b then c & d else e"},
+	} {
+		stmt, _, err := ParseWithOptions(c.input, Options{Precedence: Standard})
+		if err != nil {
+			t.Fatalf("error occurred while parsing: %v (input: %s)", err, c.input)
+		}
+		stmtStr := stmt.String()
+		if stmtStr != c.expected {
+			t.Fatalf("expected %s; got %s (input: %s)", c.expected, stmtStr, c.input)
+		}
+	}
+}
+
+func TestParseStrictError(t *testing.T) {
+	for _, input := range []string{"a & b & c", "a | b > c", "(a & b | c)", "a & (b | c) = d"} {
+		if _, _, err := Parse(input); err == nil {
+			t.Fatalf("expected '%s' to error in strict mode", input)
+		}
+	}
+}
+
+func TestParseStrictErrorMessage(t *testing.T) {
+	_, _, err := Parse("a & b | c")
+	expected := "1:7: unexpected '|'; binary operators must be parenthesized in strict precedence mode (or use " +
+		"standard precedence)"
+	if err == nil || err.Error() != expected {
+		t.Fatalf("expected error %q; got %v", expected, err)
+	}
+	// Other errors still list what was expected.
+	_, _, err = Parse("(a & b c")
+	if err == nil || strings.Contains(err.Error(), "parenthesized") {
+		t.Fatalf("expected an ordinary error; got %v", err)
+	}
+}
+
+func TestParseErrorLexerExits(t *testing.T) {
+	// The chain is rejected at its second operator, well before the lexer has sent all of its lexemes.
+	input := strings.Repeat("a & ", 100) + "a"
+	before := runtime.NumGoroutine()
+	for i := 0; i < 100; i++ {
+		if _, _, err := Parse(input); err == nil {
+			t.Fatalf("expected '%s' to error in strict mode", input)
+		}
+	}
+	// The lexer goroutines exit shortly after their channels are closed, so give them a moment to do so.
+	n := runtime.NumGoroutine()
+	for i := 0; i < 100 && n > before; i++ {
+		time.Sleep(10 * time.Millisecond)
+		n = runtime.NumGoroutine()
+	}
+	if n > before {
+		t.Fatalf("expected %d goroutines after parsing; got %d", before, n)
+	}
+}
@@ -0,0 +1,186 @@
+package vera
+
+import "fmt"
+
+// opcode is the operation performed by an instruction of a Program.
+type opcode uint8
+
+const (
+	// opConst pushes arg, which is 0 or 1.
+	opConst opcode = iota
+	// opVar pushes the value of the atomic statement with index arg in the Program's Truth.
+	opVar
+	// opNot negates the top of the stack.
+	opNot
+	// opBinary pops the right and then the left operand and pushes bit (left<<1 | right) of arg, which is the truth
+	// table of the operator.
+	opBinary
+	// opJumpFalse pops the top of the stack and continues at instruction arg if it is false.
+	opJumpFalse
+	// opJump continues at instruction arg.
+	opJump
+)
+
+type instr struct {
+	op  opcode
+	arg uint32
+}
+
+// Program is a Stmt compiled to a flat sequence of instructions for a stack machine, which evaluates faster than the
+// tree of Stmts since it does not need to recurse through interface method calls. It is created by Compile and is safe
+// for concurrent use.
+type Program struct {
+	code []instr
+	// depth is the largest number of values on the stack at once.
+	depth int
+	// truth is a Truth for the atomic statements of the Stmt, whose Names the opVar instructions index.
+	truth Truth
+}
+
+// Compile compiles the given Stmt into a Program which evaluates to the same value at every set of truth values.
+func Compile(s Stmt) *Program {
+	c := compiler{p: &Program{truth: TruthFor(s)}}
+	c.compile(s)
+	return c.p
+}
+
+// compiler holds the state of Compile.
+type compiler struct {
+	p *Program
+	// sp is the number of values on the stack after the instructions emitted so far.
+	sp int
+}
+
+// emit appends an instruction which changes the number of values on the stack by delta, returning its index.
+func (c *compiler) emit(op opcode, arg uint32, delta int) int {
+	c.p.code = append(c.p.code, instr{op, arg})
+	c.sp += delta
+	if c.sp > c.p.depth {
+		c.p.depth = c.sp
+	}
+	return len(c.p.code) - 1
+}
+
+// patch sets the target of the jump instruction at the given index to the next instruction to be emitted.
+func (c *compiler) patch(jump int) {
+	c.p.code[jump].arg = uint32(len(c.p.code))
+}
+
+func (c *compiler) compile(s Stmt) {
+	switch s := s.(type) {
+	case Const:
+		var arg uint32
+		if s {
+			arg = 1
+		}
+		c.emit(opConst, arg, 1)
+	case Var:
+		c.emit(opVar, uint32(c.p.truth.shiftMap[string(s)]), 1)
+	case Not:
+		c.compile(s.X)
+		c.emit(opNot, 0, 0)
+	case Binary:
+		c.compile(s.Left)
+		c.compile(s.Right)
+		var table uint32
+		for i := uint32(0); i < 4; i++ {
+			if s.Op.Eval(i&2 > 0, i&1 > 0) {
+				table |= 1 << i
+			}
+		}
+		c.emit(opBinary, table, -1)
+	case IfThenElse:
+		c.compile(s.Cond)
+		toElse := c.emit(opJumpFalse, 0, -1)
+		c.compile(s.Then)
+		toEnd := c.emit(opJump, 0, 0)
+		// Only one branch is evaluated, so the else branch starts with the stack as it was before the then branch.
+		c.sp--
+		c.patch(toElse)
+		c.compile(s.Else)
+		c.patch(toEnd)
+	default:
+		panic(fmt.Sprintf("vera.Compile: unexpected Stmt type %T", s))
+	}
+}
+
+// Truth returns a Truth for the atomic statements of the compiled Stmt, with every truth value set to false. The
+// Program is bound to this Truth (and its copies and clones); see Run.
+func (p *Program) Truth() Truth {
+	return p.truth.Clone()
+}
+
+// Bind returns a copy of the Program which is bound to the given Truth (and its copies and clones) instead, e.g. the
+// Truth returned by Parse along with the compiled Stmt; see Run. The Truth must include every atomic statement in the
+// compiled Stmt, but may also include others.
+func (p *Program) Bind(t Truth) (*Program, error) {
+	bound := &Program{code: make([]instr, len(p.code)), depth: p.depth, truth: t.Clone()}
+	bound.truth.Val = 0
+	for i, in := range p.code {
+		if in.op == opVar {
+			name := p.truth.Names[in.arg]
+			shift, ok := t.shiftMap[name]
+			if !ok {
+				return nil, fmt.Errorf("atomic statement %s is not in the Truth", name)
+			}
+			in.arg = uint32(shift)
+		}
+		bound.code[i] = in
+	}
+	return bound, nil
+}
+
+// Run evaluates the Program at the given set of truth values, which must include every atomic statement in the
+// compiled Stmt, as with Stmt.Eval.
+// Run is fastest for a Truth which the Program is bound to (i.e. one which shares its Names slice with the Program's
+// Truth), since the atomic statements are then read directly by index. For any other Truth, each atomic statement is
+// looked up by name (a map lookup for every occurrence in the Stmt, as Stmt.Eval does); use Bind to avoid this when
+// running the Program many times with such a Truth.
+func (p *Program) Run(t Truth) bool {
+	var buf [32]bool
+	stack := buf[:]
+	if p.depth > len(buf) {
+		stack = make([]bool, p.depth)
+	}
+	direct := len(t.Names) == len(p.truth.Names) && (len(t.Names) == 0 || &t.Names[0] == &p.truth.Names[0])
+	wide := t.isWide()
+	sp := 0
+	for pc := 0; pc < len(p.code); pc++ {
+		in := p.code[pc]
+		switch in.op {
+		case opConst:
+			stack[sp] = in.arg == 1
+			sp++
+		case opVar:
+			switch {
+			case !direct:
+				stack[sp] = t.get(p.truth.Names[in.arg])
+			case wide:
+				stack[sp] = t.Wide.Get(int(in.arg))
+			default:
+				stack[sp] = t.Val&(1<<in.arg) > 0
+			}
+			sp++
+		case opNot:
+			stack[sp-1] = !stack[sp-1]
+		case opBinary:
+			sp--
+			var i uint32
+			if stack[sp-1] {
+				i = 2
+			}
+			if stack[sp] {
+				i++
+			}
+			stack[sp-1] = in.arg&(1<<i) > 0
+		case opJumpFalse:
+			sp--
+			if !stack[sp] {
+				pc = int(in.arg) - 1
+			}
+		case opJump:
+			pc = int(in.arg) - 1
+		}
+	}
+	return stack[0]
+}
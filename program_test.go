@@ -0,0 +1,109 @@
+package vera
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestProgramRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		s := randomStmt(r, 6)
+		p := Compile(s)
+		// A Truth with extra atomic statements has different indices, so the atomic statements are looked up by name
+		// unless the Program is bound to it.
+		wider := TruthFor(s, Var("f"), Var("g"))
+		bound, err := p.Bind(wider)
+		if err != nil {
+			t.Fatalf("error occurred while binding %s: %v", s, err)
+		}
+		runs := []struct {
+			p     *Program
+			truth Truth
+		}{{p, p.Truth()}, {p, wider}, {bound, wider}}
+		for _, run := range runs {
+			truth := run.truth
+			for truth.Val = 0; truth.Val < 1<<len(truth.Names); truth.Val++ {
+				if run.p.Run(truth) != s.Eval(truth) {
+					t.Fatalf("expected %t for %s at %s", s.Eval(truth), s, truth)
+				}
+			}
+		}
+	}
+}
+
+func TestProgramBindMissing(t *testing.T) {
+	p := Compile(Binary{OpAnd, Var("a"), Var("b")})
+	if _, err := p.Bind(TruthFor(Var("a"))); err == nil {
+		t.Fatalf("expected error for binding to a Truth without b")
+	}
+}
+
+func TestProgramWide(t *testing.T) {
+	// Nest conditional expressions deeper than the fixed-size stack in Run, over more atomics than fit in Truth.Val.
+	var s Stmt = Var("x0")
+	for i := 1; i < 100; i++ {
+		s = IfThenElse{Var(fmt.Sprintf("x%d", i)), Binary{OpXor, Var(fmt.Sprintf("y%d", i)), s}, Not{Var(fmt.Sprintf("z%d", i))}}
+	}
+	p := Compile(s)
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		truth := p.Truth()
+		for _, name := range truth.Names {
+			truth.Set(name, r.Intn(2) == 0)
+		}
+		if p.Run(truth) != s.Eval(truth) {
+			t.Fatalf("expected %t at %s", s.Eval(truth), truth)
+		}
+	}
+}
+
+// benchmarkStmt returns a Stmt with 16 atomic statements and its Truth for the benchmarks.
+func benchmarkStmt(b *testing.B) (Stmt, Truth) {
+	var sb strings.Builder
+	for i := 0; i < 8; i++ {
+		if i > 0 {
+			sb.WriteString(" | ")
+		}
+		fmt.Fprintf(&sb, "((a%d -> b%d) & !(a%d ^ b%d))", i, i, (i+1)%8, (i+3)%8)
+	}
+	stmt, truth, err := ParseWithOptions(sb.String(), Options{Precedence: Standard})
+	if err != nil {
+		b.Fatalf("error occurred while parsing: %v", err)
+	}
+	return stmt, truth
+}
+
+func BenchmarkStmtEval(b *testing.B) {
+	stmt, truth := benchmarkStmt(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		truth.Val = uint64(i) & 0xFFFF
+		stmt.Eval(truth)
+	}
+}
+
+func BenchmarkProgramRun(b *testing.B) {
+	stmt, truth := benchmarkStmt(b)
+	p, err := Compile(stmt).Bind(truth)
+	if err != nil {
+		b.Fatalf("error occurred while binding: %v", err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		truth.Val = uint64(i) & 0xFFFF
+		p.Run(truth)
+	}
+}
+
+func BenchmarkProgramRunUnbound(b *testing.B) {
+	stmt, truth := benchmarkStmt(b)
+	p := Compile(stmt)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		truth.Val = uint64(i) & 0xFFFF
+		p.Run(truth)
+	}
+}
@@ -0,0 +1,312 @@
+package vera
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// RendererFor returns the Renderer for the format with the given name: "csv", "json", "markdown", "latex", or "html".
+// The boolean return value is false if there is no such format.
+func RendererFor(format string) (Renderer, bool) {
+	switch format {
+	case "csv":
+		return CSVRenderer{}, true
+	case "json":
+		return JSONRenderer{}, true
+	case "markdown":
+		return MarkdownRenderer{}, true
+	case "latex":
+		return LaTeXRenderer{}, true
+	case "html":
+		return HTMLRenderer{}, true
+	default:
+		return nil, false
+	}
+}
+
+// headers returns the headers of all the columns of the table, including the diff column if there is one.
+func (t *Table) headers() []string {
+	headers := make([]string, 0, len(t.Atomics)+len(t.Outputs)+1)
+	headers = append(headers, t.Atomics...)
+	headers = append(headers, t.Outputs...)
+	if t.Diff {
+		headers = append(headers, diffHeader)
+	}
+	return headers
+}
+
+// cells formats each value in the row with the given function, followed by the diff marker (formatted with diff) if
+// the table has a diff column.
+func (t *Table) cells(row TableRow, format func(bool) string, diff func(bool) string) []string {
+	cells := make([]string, 0, len(row.Inputs)+len(row.Outputs)+1)
+	for _, v := range row.Inputs {
+		cells = append(cells, format(v))
+	}
+	for _, v := range row.Outputs {
+		cells = append(cells, format(v))
+	}
+	if t.Diff {
+		cells = append(cells, diff(row.Differs))
+	}
+	return cells
+}
+
+// digit formats a truth value as "1" or "0".
+func digit(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
+
+// marker formats whether a row is marked in the diff column as "*" or "".
+func marker(differs bool) string {
+	if differs {
+		return "*"
+	}
+	return ""
+}
+
+// CSVRenderer is a Renderer which writes a table as comma-separated values, with a header record followed by a record
+// of 1s and 0s for each row. The diff column, if any, is also 1 or 0.
+type CSVRenderer struct{}
+
+// Render implements Renderer.
+func (CSVRenderer) Render(t *Table, out io.Writer) error {
+	w := csv.NewWriter(out)
+	if err := w.Write(t.headers()); err != nil {
+		return err
+	}
+	err := t.EachRow(func(row TableRow) error {
+		return w.Write(t.cells(row, digit, digit))
+	})
+	if err != nil {
+		return err
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// JSONRenderer is a Renderer which writes a table as a JSON array with an object for each row, mapping the name of each
+// atomic statement and each output column to its truth value (e.g. {"a": true, "b": false, "a & b": false}). The keys
+// are in the same order as the columns; a column whose header duplicates an earlier one (e.g. the output of the
+// statement "a", which is the same as its input) is omitted. If the table has a diff column, its value is under the key
+// "diff".
+type JSONRenderer struct{}
+
+// Render implements Renderer.
+func (JSONRenderer) Render(t *Table, out io.Writer) error {
+	headers := t.headers()
+	keys := make([]string, len(headers))
+	seen := make(map[string]bool, len(headers))
+	for i, header := range headers {
+		if seen[header] {
+			continue
+		}
+		seen[header] = true
+		var key bytes.Buffer
+		enc := json.NewEncoder(&key)
+		// The headers are full of '&', '<', and '>', which don't need escaping outside of HTML.
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(header); err != nil {
+			return err
+		}
+		keys[i] = strings.TrimSuffix(key.String(), "\n")
+	}
+	sep := "\n"
+	if _, err := io.WriteString(out, "["); err != nil {
+		return err
+	}
+	err := t.EachRow(func(row TableRow) error {
+		var sb strings.Builder
+		sb.WriteString(sep)
+		sb.WriteString("  {")
+		vals := append(append([]bool(nil), row.Inputs...), row.Outputs...)
+		if t.Diff {
+			vals = append(vals, row.Differs)
+		}
+		first := true
+		for i, v := range vals {
+			if keys[i] == "" {
+				continue
+			}
+			if !first {
+				sb.WriteString(", ")
+			}
+			first = false
+			_, _ = fmt.Fprintf(&sb, "%s: %t", keys[i], v)
+		}
+		sb.WriteString("}")
+		sep = ",\n"
+		_, err := io.WriteString(out, sb.String())
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if sep == "\n" {
+		// There were no rows.
+		_, err = io.WriteString(out, "]\n")
+	} else {
+		_, err = io.WriteString(out, "\n]\n")
+	}
+	return err
+}
+
+// MarkdownRenderer is a Renderer which writes a table as a GitHub Flavored Markdown table, with the headers formatted
+// as code and every column centered. Rows in which the statements differ are marked with "*" in the diff column.
+type MarkdownRenderer struct{}
+
+// Render implements Renderer.
+func (MarkdownRenderer) Render(t *Table, out io.Writer) error {
+	headers := t.headers()
+	for i, header := range headers {
+		// Pipes must be escaped even inside code spans in a table.
+		headers[i] = "`" + strings.ReplaceAll(header, "|", `\|`) + "`"
+	}
+	if err := writeMarkdownRow(headers, out); err != nil {
+		return err
+	}
+	aligns := make([]string, len(headers))
+	for i := range aligns {
+		aligns[i] = ":-:"
+	}
+	if err := writeMarkdownRow(aligns, out); err != nil {
+		return err
+	}
+	return t.EachRow(func(row TableRow) error {
+		return writeMarkdownRow(t.cells(row, digit, marker), out)
+	})
+}
+
+func writeMarkdownRow(cells []string, out io.Writer) error {
+	_, err := fmt.Fprintf(out, "| %s |\n", strings.Join(cells, " | "))
+	return err
+}
+
+// LaTeXRenderer is a Renderer which writes a table as a LaTeX tabular environment, with the headers typeset as math
+// using the conventional symbols for each operator (e.g. \land for '&') and a vertical rule between the input and
+// output columns. Rows in which the statements differ are marked with "*" in the diff column.
+type LaTeXRenderer struct{}
+
+// Render implements Renderer.
+func (LaTeXRenderer) Render(t *Table, out io.Writer) error {
+	spec := strings.Repeat("c", len(t.Atomics)) + "|" + strings.Repeat("c", len(t.Outputs))
+	headers := make([]string, 0, len(t.Atomics)+len(t.Outputs)+1)
+	for _, header := range t.Atomics {
+		headers = append(headers, "$"+latexStmt(header)+"$")
+	}
+	for _, header := range t.Outputs {
+		headers = append(headers, "$"+latexStmt(header)+"$")
+	}
+	if t.Diff {
+		spec += "|c"
+		headers = append(headers, diffHeader)
+	}
+	_, err := fmt.Fprintf(out, "\\begin{tabular}{%s}\n%s \\\\\n\\hline\n", spec, strings.Join(headers, " & "))
+	if err != nil {
+		return err
+	}
+	err = t.EachRow(func(row TableRow) error {
+		_, err := fmt.Fprintf(out, "%s \\\\\n", strings.Join(t.cells(row, digit, marker), " & "))
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(out, "\\end{tabular}\n")
+	return err
+}
+
+// latexSyms maps the symbols in a formatted Stmt to LaTeX math commands.
+var latexSyms = map[rune]string{
+	negateSym: `\lnot `,
+	andSym:    `\land`,
+	orSym:     `\lor`,
+	xorSym:    `\oplus`,
+	condSym:   `\rightarrow`,
+	bicondSym: `\leftrightarrow`,
+	nandSym:   `\uparrow`,
+	norSym:    `\downarrow`,
+	xnorSym:   `\odot`,
+}
+
+// latexStmt converts a formatted Stmt (see Stmt.String) to LaTeX math. Identifiers longer than one character are set
+// with \mathit so they are not spaced as products, and the if-then-else keywords are set upright.
+func latexStmt(s string) string {
+	var sb strings.Builder
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		if sym, ok := latexSyms[r]; ok {
+			sb.WriteString(sym)
+			continue
+		}
+		if !isIdentRune(r) {
+			sb.WriteRune(r)
+			continue
+		}
+		j := i
+		for j < len(runes) && isIdentRune(runes[j]) {
+			j++
+		}
+		word := string(runes[i:j])
+		i = j - 1
+		switch {
+		case word == "if" || word == "then" || word == "else":
+			sb.WriteString(`\;\mathrm{` + word + `}\;`)
+		case len(word) == 1 && word != "_":
+			sb.WriteString(word)
+		default:
+			sb.WriteString(`\mathit{` + strings.ReplaceAll(word, "_", `\_`) + `}`)
+		}
+	}
+	return sb.String()
+}
+
+// isIdentRune returns whether the rune can appear in an identifier or constant.
+func isIdentRune(r rune) bool {
+	return r == '_' || r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
+
+// HTMLRenderer is a Renderer which writes a table as an HTML table element, with the headers in a thead and the rows in
+// a tbody. Rows in which the statements differ are marked with "*" in the diff column.
+type HTMLRenderer struct{}
+
+// Render implements Renderer.
+func (HTMLRenderer) Render(t *Table, out io.Writer) error {
+	if _, err := io.WriteString(out, "<table>\n<thead>\n"); err != nil {
+		return err
+	}
+	if err := writeHTMLRow("th", t.headers(), out); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(out, "</thead>\n<tbody>\n"); err != nil {
+		return err
+	}
+	err := t.EachRow(func(row TableRow) error {
+		return writeHTMLRow("td", t.cells(row, digit, marker), out)
+	})
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(out, "</tbody>\n</table>\n")
+	return err
+}
+
+func writeHTMLRow(tag string, cells []string, out io.Writer) error {
+	var sb strings.Builder
+	sb.WriteString("<tr>")
+	for _, cell := range cells {
+		_, _ = fmt.Fprintf(&sb, "<%s>%s</%s>", tag, html.EscapeString(cell), tag)
+	}
+	sb.WriteString("</tr>\n")
+	_, err := io.WriteString(out, sb.String())
+	return err
+}
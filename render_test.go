@@ -0,0 +1,102 @@
+package vera
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderers(t *testing.T) {
+	a, _, err := Parse("a > b_1")
+	if err != nil {
+		t.Fatalf("error occurred while parsing: %v", err)
+	}
+	b, _, err := Parse("!a | b_1")
+	if err != nil {
+		t.Fatalf("error occurred while parsing: %v", err)
+	}
+	c, _, err := Parse("b_1 | a")
+	if err != nil {
+		t.Fatalf("error occurred while parsing: %v", err)
+	}
+	type testCase struct {
+		format   string
+		expected string
+	}
+	for _, tc := range []testCase{
+		{"csv", `a,b_1,a > b_1,!a | b_1,b_1 | a,diff
+0,0,1,1,0,1
+0,1,1,1,1,0
+1,0,0,0,1,1
+1,1,1,1,1,0
+`},
+		{"json", `[
+  {"a": false, "b_1": false, "a > b_1": true, "!a | b_1": true, "b_1 | a": false, "diff": true},
+  {"a": false, "b_1": true, "a > b_1": true, "!a | b_1": true, "b_1 | a": true, "diff": false},
+  {"a": true, "b_1": false, "a > b_1": false, "!a | b_1": false, "b_1 | a": true, "diff": true},
+  {"a": true, "b_1": true, "a > b_1": true, "!a | b_1": true, "b_1 | a": true, "diff": false}
+]
+`},
+		{"markdown", "| `a` | `b_1` | `a > b_1` | `!a \\| b_1` | `b_1 \\| a` | `diff` |\n" +
+			"| :-: | :-: | :-: | :-: | :-: | :-: |\n" +
+			"| 0 | 0 | 1 | 1 | 0 | * |\n" +
+			"| 0 | 1 | 1 | 1 | 1 |  |\n" +
+			"| 1 | 0 | 0 | 0 | 1 | * |\n" +
+			"| 1 | 1 | 1 | 1 | 1 |  |\n"},
+		{"latex", `\begin{tabular}{cc|ccc|c}
+$a$ & $\mathit{b\_1}$ & $a \rightarrow \mathit{b\_1}$ & $\lnot a \lor \mathit{b\_1}$ & $\mathit{b\_1} \lor a$ & diff \\
+\hline
+0 & 0 & 1 & 1 & 0 & * \\
+0 & 1 & 1 & 1 & 1 &  \\
+1 & 0 & 0 & 0 & 1 & * \\
+1 & 1 & 1 & 1 & 1 &  \\
+\end{tabular}
+`},
+		{"html", `<table>
+<thead>
+<tr><th>a</th><th>b_1</th><th>a &gt; b_1</th><th>!a | b_1</th><th>b_1 | a</th><th>diff</th></tr>
+</thead>
+<tbody>
+<tr><td>0</td><td>0</td><td>1</td><td>1</td><td>0</td><td>*</td></tr>
+<tr><td>0</td><td>1</td><td>1</td><td>1</td><td>1</td><td></td></tr>
+<tr><td>1</td><td>0</td><td>0</td><td>0</td><td>1</td><td>*</td></tr>
+<tr><td>1</td><td>1</td><td>1</td><td>1</td><td>1</td><td></td></tr>
+</tbody>
+</table>
+`},
+	} {
+		table, err := NewTable([]Stmt{a, b, c}, TruthFor(a, b, c), TTOptions{Diff: true})
+		if err != nil {
+			t.Fatalf("error occurred while creating table: %v", err)
+		}
+		r, ok := RendererFor(tc.format)
+		if !ok {
+			t.Fatalf("expected a renderer for format '%s'", tc.format)
+		}
+		var sb strings.Builder
+		if err := r.Render(table, &sb); err != nil {
+			t.Fatalf("error occurred while rendering %s: %v", tc.format, err)
+		}
+		if sb.String() != tc.expected {
+			t.Errorf("expected %s:\n%s\ngot:\n%s", tc.format, tc.expected, sb.String())
+		}
+	}
+	if _, ok := RendererFor("yaml"); ok {
+		t.Fatal("expected no renderer for format 'yaml'")
+	}
+}
+
+func TestJSONRendererDuplicateKeys(t *testing.T) {
+	// The output column of "a" has the same header as its input column, so it is omitted.
+	table, err := NewTable([]Stmt{Var("a")}, TruthFor(Var("a")), TTOptions{})
+	if err != nil {
+		t.Fatalf("error occurred while creating table: %v", err)
+	}
+	var sb strings.Builder
+	if err := (JSONRenderer{}).Render(table, &sb); err != nil {
+		t.Fatalf("error occurred while rendering: %v", err)
+	}
+	expected := "[\n  {\"a\": false},\n  {\"a\": true}\n]\n"
+	if sb.String() != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, sb.String())
+	}
+}
@@ -0,0 +1,434 @@
+package vera
+
+// Satisfiable reports whether there is a set of truth values at which the given Stmt evaluates to true and, if so,
+// returns one such set. Unlike iterating over every Truth.Val, it does not enumerate the 2^n sets of truth values; the
+// Stmt is converted to CNF via the Tseitin transformation and solved with a CDCL (conflict-driven clause learning) SAT
+// solver, so it is practical for Stmts with far more atomic statements than could be put in a truth table. If the Stmt
+// is unsatisfiable, the returned Truth has every truth value set to false.
+func Satisfiable(s Stmt) (Truth, bool) {
+	truth := TruthFor(s)
+	c := tseitin(s)
+	sv := newSolver(c)
+	if !sv.solve() {
+		return truth, false
+	}
+	sv.model(c, &truth)
+	return truth, true
+}
+
+// Values of solver.assigns.
+const (
+	lFalse int8 = -1
+	lUndef int8 = 0
+	lTrue  int8 = 1
+)
+
+// noReason is the reason for an assignment which was a decision (or a unit clause) rather than an implication.
+const noReason = -1
+
+// solver is a CDCL SAT solver using two watched literals for unit propagation, first-UIP clause learning with
+// non-chronological backtracking, VSIDS-style variable activities, phase saving, and geometric restarts.
+type solver struct {
+	nVars   int
+	clauses []clause
+	// watches holds, for each literal (indexed by litIdx), the indices of the clauses which are watching it. The two
+	// watched literals of a clause are always its first two literals.
+	watches [][]int
+	// assigns, level, and reason are indexed by variable.
+	assigns []int8
+	level   []int
+	reason  []int
+	// phase holds the last value assigned to each variable, which is reused when the variable is next decided.
+	phase    []bool
+	activity []float64
+	varInc   float64
+	// order is a max-heap of variables ordered by activity. It contains every unassigned variable (and possibly some
+	// assigned ones, which are skipped when popped).
+	order varHeap
+	// trail holds the assigned literals in the order they were assigned; trailLim holds the index in trail at which
+	// each decision level starts; qhead is the index in trail of the next literal to propagate.
+	trail    []lit
+	trailLim []int
+	qhead    int
+	// ok is false if a conflict was found at decision level 0 (i.e. the clauses are unsatisfiable).
+	ok bool
+	// seen is scratch space for analyze, indexed by variable.
+	seen []bool
+}
+
+func newSolver(c *cnf) *solver {
+	n := c.nVars
+	sv := &solver{
+		nVars:    n,
+		watches:  make([][]int, 2*(n+1)),
+		assigns:  make([]int8, n+1),
+		level:    make([]int, n+1),
+		reason:   make([]int, n+1),
+		phase:    make([]bool, n+1),
+		activity: make([]float64, n+1),
+		varInc:   1,
+		ok:       true,
+		seen:     make([]bool, n+1),
+	}
+	sv.order = varHeap{activity: sv.activity, pos: make([]int, n+1)}
+	for v := 1; v <= n; v++ {
+		sv.order.pos[v] = -1
+		sv.order.push(v)
+	}
+	for _, cl := range c.clauses {
+		sv.addClause(cl)
+	}
+	return sv
+}
+
+// litIdx returns the index of the given literal in solver.watches.
+func litIdx(l lit) int {
+	if l < 0 {
+		return 2*int(-l) + 1
+	}
+	return 2 * int(l)
+}
+
+// value returns the current value of the given literal.
+func (sv *solver) value(l lit) int8 {
+	a := sv.assigns[l.v()]
+	if l < 0 {
+		return -a
+	}
+	return a
+}
+
+func (sv *solver) decisionLevel() int {
+	return len(sv.trailLim)
+}
+
+// addClause adds a clause to the solver, first backtracking to decision level 0 (which discards any model found by
+// solve). Duplicate literals and literals which are false at level 0 are removed, and clauses which are tautologies or
+// already satisfied are discarded.
+func (sv *solver) addClause(cl clause) {
+	sv.backtrack(0)
+	if !sv.ok {
+		return
+	}
+	present := make(map[lit]bool, len(cl))
+	simplified := make(clause, 0, len(cl))
+	for _, l := range cl {
+		switch {
+		case present[-l] || sv.value(l) == lTrue:
+			return
+		case present[l] || sv.value(l) == lFalse:
+			continue
+		}
+		present[l] = true
+		simplified = append(simplified, l)
+	}
+	switch len(simplified) {
+	case 0:
+		sv.ok = false
+	case 1:
+		sv.enqueue(simplified[0], noReason)
+		if sv.propagate() != noReason {
+			sv.ok = false
+		}
+	default:
+		sv.attach(simplified)
+	}
+}
+
+// attach adds the given clause (which must have at least two literals) to the clause database, watching its first two
+// literals, and returns its index.
+func (sv *solver) attach(cl clause) int {
+	idx := len(sv.clauses)
+	sv.clauses = append(sv.clauses, cl)
+	sv.watches[litIdx(-cl[0])] = append(sv.watches[litIdx(-cl[0])], idx)
+	sv.watches[litIdx(-cl[1])] = append(sv.watches[litIdx(-cl[1])], idx)
+	return idx
+}
+
+// enqueue assigns the given literal to true at the current decision level.
+func (sv *solver) enqueue(l lit, reason int) {
+	v := l.v()
+	if l > 0 {
+		sv.assigns[v] = lTrue
+	} else {
+		sv.assigns[v] = lFalse
+	}
+	sv.level[v] = sv.decisionLevel()
+	sv.reason[v] = reason
+	sv.trail = append(sv.trail, l)
+}
+
+// propagate performs unit propagation on all enqueued literals, returning the index of a conflicting clause or
+// noReason if there is no conflict.
+// Note that clauses are registered in watches under the negation of each watched literal, so when a literal p becomes
+// true, watches[litIdx(p)] holds exactly the clauses whose watched literal just became false.
+func (sv *solver) propagate() int {
+	for sv.qhead < len(sv.trail) {
+		p := sv.trail[sv.qhead]
+		sv.qhead++
+		falseLit := -p
+		ws := sv.watches[litIdx(p)]
+		kept := ws[:0]
+		conflict := noReason
+		for i, ci := range ws {
+			if conflict != noReason {
+				kept = append(kept, ws[i:]...)
+				break
+			}
+			cl := sv.clauses[ci]
+			// Make sure the false literal is cl[1].
+			if cl[0] == falseLit {
+				cl[0], cl[1] = cl[1], cl[0]
+			}
+			if sv.value(cl[0]) == lTrue {
+				kept = append(kept, ci)
+				continue
+			}
+			// Look for a new literal to watch.
+			moved := false
+			for k := 2; k < len(cl); k++ {
+				if sv.value(cl[k]) != lFalse {
+					cl[1], cl[k] = cl[k], cl[1]
+					sv.watches[litIdx(-cl[1])] = append(sv.watches[litIdx(-cl[1])], ci)
+					moved = true
+					break
+				}
+			}
+			if moved {
+				continue
+			}
+			// The clause is unit or conflicting.
+			kept = append(kept, ci)
+			if sv.value(cl[0]) == lFalse {
+				conflict = ci
+			} else {
+				sv.enqueue(cl[0], ci)
+			}
+		}
+		sv.watches[litIdx(p)] = kept
+		if conflict != noReason {
+			return conflict
+		}
+	}
+	return noReason
+}
+
+// analyze derives a learnt clause from the given conflicting clause using the first UIP scheme. The asserting literal
+// is the first literal of the returned clause. The level to backtrack to is also returned.
+func (sv *solver) analyze(conflict int) (clause, int) {
+	learnt := clause{0}
+	pathCount := 0
+	var p lit
+	idx := len(sv.trail) - 1
+	for {
+		for _, q := range sv.clauses[conflict] {
+			if q == p {
+				// The implied literal itself (only present once p is set, i.e. after the first iteration).
+				continue
+			}
+			v := q.v()
+			if sv.seen[v] || sv.level[v] == 0 {
+				continue
+			}
+			sv.seen[v] = true
+			sv.bumpVar(v)
+			if sv.level[v] == sv.decisionLevel() {
+				pathCount++
+			} else {
+				learnt = append(learnt, q)
+			}
+		}
+		// Find the next literal on the trail to expand.
+		for !sv.seen[sv.trail[idx].v()] {
+			idx--
+		}
+		p = sv.trail[idx]
+		idx--
+		sv.seen[p.v()] = false
+		pathCount--
+		if pathCount == 0 {
+			break
+		}
+		conflict = sv.reason[p.v()]
+	}
+	learnt[0] = -p
+	for _, q := range learnt[1:] {
+		sv.seen[q.v()] = false
+	}
+	// The backtrack level is the highest level among the other literals, which is placed second so that it is
+	// watched.
+	btLevel := 0
+	for i := 1; i < len(learnt); i++ {
+		if lv := sv.level[learnt[i].v()]; lv > btLevel {
+			btLevel = lv
+			learnt[1], learnt[i] = learnt[i], learnt[1]
+		}
+	}
+	return learnt, btLevel
+}
+
+// bumpVar increases the activity of the given variable, rescaling all activities if they grow too large.
+func (sv *solver) bumpVar(v int) {
+	sv.activity[v] += sv.varInc
+	if sv.activity[v] > 1e100 {
+		// Scaling every activity by the same factor preserves the heap order.
+		for i := range sv.activity {
+			sv.activity[i] *= 1e-100
+		}
+		sv.varInc *= 1e-100
+	}
+	if sv.order.pos[v] >= 0 {
+		sv.order.up(sv.order.pos[v])
+	}
+}
+
+// backtrack undoes all assignments above the given decision level.
+func (sv *solver) backtrack(level int) {
+	if sv.decisionLevel() <= level {
+		return
+	}
+	for i := len(sv.trail) - 1; i >= sv.trailLim[level]; i-- {
+		v := sv.trail[i].v()
+		sv.phase[v] = sv.assigns[v] == lTrue
+		sv.assigns[v] = lUndef
+		if sv.order.pos[v] < 0 {
+			sv.order.push(v)
+		}
+	}
+	sv.trail = sv.trail[:sv.trailLim[level]]
+	sv.trailLim = sv.trailLim[:level]
+	sv.qhead = len(sv.trail)
+}
+
+// pickBranchVar returns the unassigned variable with the highest activity, or 0 if every variable is assigned.
+func (sv *solver) pickBranchVar() int {
+	for len(sv.order.heap) > 0 {
+		if v := sv.order.pop(); sv.assigns[v] == lUndef {
+			return v
+		}
+	}
+	return 0
+}
+
+// solve searches for a satisfying assignment, returning whether one was found. If so, the assignment is left in
+// sv.assigns until the next call to solve or addClause (which must be preceded by backtracking to level 0).
+func (sv *solver) solve() bool {
+	sv.backtrack(0)
+	if !sv.ok {
+		return false
+	}
+	if sv.propagate() != noReason {
+		sv.ok = false
+		return false
+	}
+	restartLimit := 100.0
+	conflicts := 0
+	for {
+		if conflict := sv.propagate(); conflict != noReason {
+			if sv.decisionLevel() == 0 {
+				sv.ok = false
+				return false
+			}
+			conflicts++
+			learnt, btLevel := sv.analyze(conflict)
+			sv.backtrack(btLevel)
+			if len(learnt) == 1 {
+				sv.enqueue(learnt[0], noReason)
+			} else {
+				sv.enqueue(learnt[0], sv.attach(learnt))
+			}
+			// Decay activities by increasing the amount future bumps add.
+			sv.varInc /= 0.95
+			continue
+		}
+		if float64(conflicts) >= restartLimit {
+			conflicts = 0
+			restartLimit *= 1.5
+			sv.backtrack(0)
+			continue
+		}
+		v := sv.pickBranchVar()
+		if v == 0 {
+			return true
+		}
+		sv.trailLim = append(sv.trailLim, len(sv.trail))
+		if sv.phase[v] {
+			sv.enqueue(lit(v), noReason)
+		} else {
+			sv.enqueue(-lit(v), noReason)
+		}
+	}
+}
+
+// model copies the values of the atomic statements in the given cnf from the solver's current (satisfying) assignment
+// into the given Truth.
+func (sv *solver) model(c *cnf, truth *Truth) {
+	for name, v := range c.vars {
+		truth.Set(name, sv.assigns[v] == lTrue)
+	}
+}
+
+// varHeap is a binary max-heap of variables ordered by activity, which also tracks the position of each variable in
+// the heap so that a variable can be moved up when its activity is bumped.
+type varHeap struct {
+	heap     []int
+	activity []float64
+	// pos holds the index of each variable in heap, or -1 if the variable is not in the heap.
+	pos []int
+}
+
+func (h *varHeap) less(i, j int) bool {
+	return h.activity[h.heap[i]] > h.activity[h.heap[j]]
+}
+
+func (h *varHeap) swap(i, j int) {
+	h.heap[i], h.heap[j] = h.heap[j], h.heap[i]
+	h.pos[h.heap[i]] = i
+	h.pos[h.heap[j]] = j
+}
+
+func (h *varHeap) up(i int) {
+	for i > 0 {
+		parent := (i - 1) / 2
+		if !h.less(i, parent) {
+			return
+		}
+		h.swap(i, parent)
+		i = parent
+	}
+}
+
+func (h *varHeap) down(i int) {
+	for {
+		child := 2*i + 1
+		if child >= len(h.heap) {
+			return
+		}
+		if child+1 < len(h.heap) && h.less(child+1, child) {
+			child++
+		}
+		if !h.less(child, i) {
+			return
+		}
+		h.swap(i, child)
+		i = child
+	}
+}
+
+func (h *varHeap) push(v int) {
+	h.heap = append(h.heap, v)
+	h.pos[v] = len(h.heap) - 1
+	h.up(len(h.heap) - 1)
+}
+
+func (h *varHeap) pop() int {
+	v := h.heap[0]
+	last := len(h.heap) - 1
+	h.swap(0, last)
+	h.heap = h.heap[:last]
+	h.pos[v] = -1
+	if last > 0 {
+		h.down(0)
+	}
+	return v
+}
@@ -0,0 +1,118 @@
+package vera
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// randomStmt returns a random Stmt of the given depth over the atomic statements a-e.
+func randomStmt(r *rand.Rand, depth int) Stmt {
+	if depth == 0 || r.Intn(4) == 0 {
+		if r.Intn(10) == 0 {
+			return Const(r.Intn(2) == 0)
+		}
+		return Var(string(rune('a' + r.Intn(5))))
+	}
+	switch r.Intn(6) {
+	case 0:
+		return Not{randomStmt(r, depth-1)}
+	case 1:
+		return IfThenElse{randomStmt(r, depth-1), randomStmt(r, depth-1), randomStmt(r, depth-1)}
+	default:
+		return Binary{Op(r.Intn(int(OpXnor) + 1)), randomStmt(r, depth-1), randomStmt(r, depth-1)}
+	}
+}
+
+// bruteSatisfiable checks satisfiability by evaluating the Stmt at every set of truth values.
+func bruteSatisfiable(s Stmt) bool {
+	truth := TruthFor(s)
+	for truth.Val = 0; truth.Val < 1<<len(truth.Names); truth.Val++ {
+		if s.Eval(truth) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestSatisfiableRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 2000; i++ {
+		s := randomStmt(r, 5)
+		truth, sat := Satisfiable(s)
+		if sat != bruteSatisfiable(s) {
+			t.Fatalf("expected satisfiable=%t for %s", !sat, s)
+		}
+		if sat && !s.Eval(truth) {
+			t.Fatalf("model %s does not satisfy %s", truth, s)
+		}
+	}
+}
+
+func TestSatisfiableConstants(t *testing.T) {
+	if _, sat := Satisfiable(Const(true)); !sat {
+		t.Fatal("expected 1 to be satisfiable")
+	}
+	if _, sat := Satisfiable(Const(false)); sat {
+		t.Fatal("expected 0 to be unsatisfiable")
+	}
+}
+
+// pigeonhole returns a Stmt asserting that n+1 pigeons can be placed in n holes with no two pigeons sharing a hole.
+func pigeonhole(n int) Stmt {
+	var s Stmt = Const(true)
+	p := func(i, j int) Stmt {
+		return Var(fmt.Sprintf("p%d_%d", i, j))
+	}
+	for i := 0; i <= n; i++ {
+		var some Stmt = Const(false)
+		for j := 0; j < n; j++ {
+			some = Binary{OpOr, some, p(i, j)}
+		}
+		s = Binary{OpAnd, s, some}
+	}
+	for j := 0; j < n; j++ {
+		for i := 0; i <= n; i++ {
+			for k := i + 1; k <= n; k++ {
+				s = Binary{OpAnd, s, Binary{OpNand, p(i, j), p(k, j)}}
+			}
+		}
+	}
+	return s
+}
+
+func TestSatisfiablePigeonhole(t *testing.T) {
+	if _, sat := Satisfiable(pigeonhole(6)); sat {
+		t.Fatal("expected pigeonhole(6) to be unsatisfiable")
+	}
+}
+
+func TestSatisfiableWide(t *testing.T) {
+	// A chain of 300 implications x0 > x1 > ... > x299 with x0 true and x299 false is unsatisfiable; without x299
+	// false, it is satisfiable only if every atomic is true.
+	var sb strings.Builder
+	sb.WriteString("x0")
+	for i := 1; i < 300; i++ {
+		fmt.Fprintf(&sb, " & (x%d > x%d)", i-1, i)
+	}
+	stmt, _, err := ParseWithOptions(sb.String(), Options{Precedence: Standard})
+	if err != nil {
+		t.Fatalf("error occurred while parsing: %v", err)
+	}
+	truth, sat := Satisfiable(stmt)
+	if !sat {
+		t.Fatal("expected chain to be satisfiable")
+	}
+	for _, name := range truth.Names {
+		if v, _ := truth.Get(name); !v {
+			t.Fatalf("expected %s to be true in model", name)
+		}
+	}
+	if !stmt.Eval(truth) {
+		t.Fatal("model does not satisfy chain")
+	}
+	if _, sat := Satisfiable(Binary{OpAnd, stmt, Not{Var("x299")}}); sat {
+		t.Fatal("expected chain with x299 false to be unsatisfiable")
+	}
+}
@@ -0,0 +1,228 @@
+package vera
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// Stmt is a logical statement which can be evaluated at a set of truth values. The concrete type of a Stmt returned by
+// Parse is always one of Const, Var, Not, Binary, or IfThenElse, so analyses may use a type switch to inspect the tree:
+//
+//	switch s := stmt.(type) {
+//	case vera.Binary:
+//		// Do something with s.Op, s.Left, and s.Right.
+//	...
+//	}
+type Stmt interface {
+	fmt.Stringer
+	Eval(Truth) bool
+}
+
+// surroundIfCompound returns the string representation of the given Stmt and surrounds it in parentheses if it is a
+// Binary or IfThenElse.
+func surroundIfCompound(s Stmt) string {
+	switch s.(type) {
+	case Binary, IfThenElse:
+		return "(" + s.String() + ")"
+	}
+	return s.String()
+}
+
+// Const is a constant statement: "1" (true) or "0" (false).
+type Const bool
+
+func (s Const) Eval(Truth) bool {
+	return bool(s)
+}
+
+func (s Const) String() string {
+	if s {
+		return "1"
+	}
+	return "0"
+}
+
+// Var is an atomic statement, identified by its name.
+type Var string
+
+func (s Var) Eval(t Truth) bool {
+	return t.get(string(s))
+}
+
+func (s Var) String() string {
+	return string(s)
+}
+
+// Not is the negation of a statement: "!X".
+type Not struct {
+	X Stmt
+}
+
+func (s Not) Eval(t Truth) bool {
+	return !s.X.Eval(t)
+}
+
+func (s Not) String() string {
+	return "!" + surroundIfCompound(s.X)
+}
+
+// Binary is two statements joined by a binary operator: "Left Op Right".
+type Binary struct {
+	Op    Op
+	Left  Stmt
+	Right Stmt
+}
+
+func (s Binary) Eval(t Truth) bool {
+	return s.Op.Eval(s.Left.Eval(t), s.Right.Eval(t))
+}
+
+func (s Binary) String() string {
+	return surroundIfCompound(s.Left) + " " + s.Op.String() + " " + surroundIfCompound(s.Right)
+}
+
+// IfThenElse is a conditional expression: "if Cond then Then else Else".
+type IfThenElse struct {
+	Cond Stmt
+	Then Stmt
+	Else Stmt
+}
+
+func (s IfThenElse) Eval(t Truth) bool {
+	if s.Cond.Eval(t) {
+		return s.Then.Eval(t)
+	}
+	return s.Else.Eval(t)
+}
+
+func (s IfThenElse) String() string {
+	// The keywords delimit each operand, so there is no need to parenthesize them.
+	return "if " + s.Cond.String() + " then " + s.Then.String() + " else " + s.Else.String()
+}
+
+// Op is a binary logical operator.
+type Op byte
+
+const (
+	OpAnd    Op = iota // &
+	OpOr               // |
+	OpXor              // ^
+	OpCond             // >
+	OpBicond           // =
+	OpNand             // ↑
+	OpNor              // ↓
+	OpXnor             // ⊙
+)
+
+// operator represents the truth function of a binary logical operator.
+type operator func(bool, bool) bool
+
+// wordOperator applies the truth function of a binary logical operator to each of the 64 pairs of bits in two words.
+type wordOperator func(uint64, uint64) uint64
+
+// opInfo holds the truth function, bitwise truth function, and canonical symbol of each Op, indexed by Op.
+var opInfo = [...]struct {
+	f   operator
+	w   wordOperator
+	sym rune
+}{
+	OpAnd:    {and, andWord, andSym},
+	OpOr:     {or, orWord, orSym},
+	OpXor:    {xor, xorWord, xorSym},
+	OpCond:   {cond, condWord, condSym},
+	OpBicond: {bicond, bicondWord, bicondSym},
+	OpNand:   {nand, nandWord, nandSym},
+	OpNor:    {nor, norWord, norSym},
+	OpXnor:   {xnor, xnorWord, xnorSym},
+}
+
+// Eval applies the operator to the given operands.
+func (op Op) Eval(left bool, right bool) bool {
+	return opInfo[op].f(left, right)
+}
+
+// evalWord applies the operator to each pair of corresponding bits in the given words.
+func (op Op) evalWord(left uint64, right uint64) uint64 {
+	return opInfo[op].w(left, right)
+}
+
+// String returns the canonical symbol of the operator (e.g. "&" for OpAnd).
+func (op Op) String() string {
+	return string(opInfo[op].sym)
+}
+
+func and(left bool, right bool) bool {
+	return left && right
+}
+
+func or(left bool, right bool) bool {
+	return left || right
+}
+
+func xor(left bool, right bool) bool {
+	return (left && !right) || (!left && right)
+}
+
+func cond(left bool, right bool) bool {
+	return !left || right
+}
+
+func bicond(left bool, right bool) bool {
+	return left == right
+}
+
+func nand(left bool, right bool) bool {
+	return !(left && right)
+}
+
+func nor(left bool, right bool) bool {
+	return !(left || right)
+}
+
+func xnor(left bool, right bool) bool {
+	return left == right
+}
+
+func andWord(left uint64, right uint64) uint64 {
+	return left & right
+}
+
+func orWord(left uint64, right uint64) uint64 {
+	return left | right
+}
+
+func xorWord(left uint64, right uint64) uint64 {
+	return left ^ right
+}
+
+func condWord(left uint64, right uint64) uint64 {
+	return ^left | right
+}
+
+func bicondWord(left uint64, right uint64) uint64 {
+	return ^(left ^ right)
+}
+
+func nandWord(left uint64, right uint64) uint64 {
+	return ^(left & right)
+}
+
+func norWord(left uint64, right uint64) uint64 {
+	return ^(left | right)
+}
+
+func xnorWord(left uint64, right uint64) uint64 {
+	return ^(left ^ right)
+}
+
+// symToOp takes an operator symbol and returns the associated Op.
+func symToOp(sym string) Op {
+	r, _ := utf8.DecodeRuneInString(sym)
+	for op, info := range opInfo {
+		if info.sym == r {
+			return Op(op)
+		}
+	}
+	// Lexer should guarantee this never happens.
+	panic(fmt.Sprintf("invalid op symbol '%s'", sym))
+}
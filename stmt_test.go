@@ -0,0 +1,51 @@
+package vera
+
+import "testing"
+
+func TestParseTree(t *testing.T) {
+	stmt, _, err := Parse("!(a & 1) > if b then c else 0")
+	if err != nil {
+		t.Fatalf("error occurred while parsing: %v", err)
+	}
+	expected := Binary{
+		Op:    OpCond,
+		Left:  Not{Binary{OpAnd, Var("a"), Const(true)}},
+		Right: IfThenElse{Var("b"), Var("c"), Const(false)},
+	}
+	if stmt != Stmt(expected) {
+		t.Fatalf("expected %#v; got %#v", expected, stmt)
+	}
+}
+
+func TestOp(t *testing.T) {
+	type testCase struct {
+		op       Op
+		sym      string
+		expected [4]bool
+	}
+	for _, c := range []testCase{
+		{OpAnd, "&", [4]bool{false, false, false, true}},
+		{OpOr, "|", [4]bool{false, true, true, true}},
+		{OpXor, "^", [4]bool{false, true, true, false}},
+		{OpCond, ">", [4]bool{true, true, false, true}},
+		{OpBicond, "=", [4]bool{true, false, false, true}},
+		{OpNand, "↑", [4]bool{true, true, true, false}},
+		{OpNor, "↓", [4]bool{true, false, false, false}},
+		{OpXnor, "⊙", [4]bool{true, false, false, true}},
+	} {
+		if c.op.String() != c.sym {
+			t.Fatalf("expected symbol %s; got %s", c.sym, c.op)
+		}
+		// The bits of 0b1100 and 0b1010 pair up as the operands in the same order as the expected values (from the
+		// least significant bit).
+		word := c.op.evalWord(0b1100, 0b1010)
+		for i, exp := range c.expected {
+			if c.op.Eval(i&2 > 0, i&1 > 0) != exp {
+				t.Fatalf("expected %t for %t %s %t", exp, i&2 > 0, c.op, i&1 > 0)
+			}
+			if (word>>i)&1 == 1 != exp {
+				t.Fatalf("expected bit %d of bitwise %s to be %t", i, c.op, exp)
+			}
+		}
+	}
+}
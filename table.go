@@ -0,0 +1,185 @@
+package vera
+
+import (
+	"errors"
+	"io"
+)
+
+// Table is the contents of a truth table, independent of how it is displayed; a Renderer writes it in a particular
+// format. The rows are evaluated as they are rendered, so a large table does not need to fit in memory.
+type Table struct {
+	// Atomics are the names of the atomic statements in the input columns, in lexicographic order.
+	Atomics []string
+	// Outputs are the headers of the output columns, i.e. the formatted Stmts.
+	Outputs []string
+	// Diff is whether the table has a final column marking the rows where the Stmts do not all have the same value (see
+	// TTOptions.Diff).
+	Diff bool
+
+	columns []Stmt
+	// words are the compiled columns (see compileWords), if the rows are every set of truth values in order.
+	words []wordFunc
+	// rows calls yield with the Truth for each row in order, stopping at the first error.
+	rows func(yield func(Truth) error) error
+	diff func(Truth) bool
+}
+
+// TableRow is a row of a Table.
+type TableRow struct {
+	// Inputs are the truth values of the atomic statements, in the same order as Table.Atomics.
+	Inputs []bool
+	// Outputs are the values of the output columns, in the same order as Table.Outputs.
+	Outputs []bool
+	// Differs is whether the Stmts do not all have the same value in this row. It is always false if Table.Diff is not
+	// set.
+	Differs bool
+}
+
+// Renderer writes a Table in a particular format.
+type Renderer interface {
+	Render(t *Table, out io.Writer) error
+}
+
+// diffHeader is the header of the column added to tables of several Stmts by TTOptions.Diff.
+const diffHeader = "diff"
+
+// NewTable creates a truth table with an output column for each of the given Stmts (configured by the given TTOptions)
+// and a row for every set of truth values of the atomic statements in the given Truth, which must include every atomic
+// statement in the Stmts (e.g. the Truth returned by Parse or TruthFor).
+func NewTable(stmts []Stmt, truth Truth, opts TTOptions) (*Table, error) {
+	if len(stmts) == 0 {
+		return nil, errors.New("cannot make a truth table with no statements")
+	}
+	if len(truth.Names) == 0 {
+		return nil, errors.New("cannot make a truth table with no atomics")
+	}
+	if len(truth.Names) >= 64 {
+		return nil, errors.New("cannot make a truth table with 64 or more atomics")
+	}
+	columns := stmts
+	if opts.Steps {
+		columns = subexpressions(stmts...)
+	}
+	t := newTable(columns, truth.Names)
+	t.words = make([]wordFunc, len(columns))
+	for i, column := range columns {
+		f, err := compileWords(column, truth)
+		if err != nil {
+			return nil, err
+		}
+		t.words[i] = f
+	}
+	truth.Val = 0
+	n := uint64(1) << len(truth.Names)
+	t.rows = func(yield func(Truth) error) error {
+		for row := truth; row.Val < n; row.Val++ {
+			if err := yield(row); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if opts.Diff && len(stmts) > 1 {
+		t.Diff = true
+		t.diff = func(truth Truth) bool {
+			first := stmts[0].Eval(truth)
+			for _, stmt := range stmts[1:] {
+				if stmt.Eval(truth) != first {
+					return true
+				}
+			}
+			return false
+		}
+	}
+	return t, nil
+}
+
+// NewRowsTable creates a table with an output column for each of the given Stmts and only the given rows of truth
+// values. The input columns are the union of the atomic statements in the Stmts (see TruthFor), so each row must have a
+// truth value for each of them.
+func NewRowsTable(stmts []Stmt, rows []Truth) *Table {
+	t := newTable(stmts, TruthFor(stmts...).Names)
+	t.rows = func(yield func(Truth) error) error {
+		for _, row := range rows {
+			if err := yield(row); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return t
+}
+
+// newTable creates a table with the given output columns and input columns for the given atomic statements (in the
+// descending order of Truth.Names), without any rows.
+func newTable(columns []Stmt, names []string) *Table {
+	t := &Table{
+		Atomics: make([]string, len(names)),
+		Outputs: make([]string, len(columns)),
+		columns: columns,
+	}
+	for i, name := range names {
+		t.Atomics[len(names)-1-i] = name
+	}
+	for i, column := range columns {
+		t.Outputs[i] = column.String()
+	}
+	return t
+}
+
+// EachRow calls f with each row of the table in order, stopping at and returning the first error returned by f. The
+// slices in the TableRow are reused between calls, so f must copy them if it needs to keep them.
+func (t *Table) EachRow(f func(TableRow) error) error {
+	row := TableRow{
+		Inputs:  make([]bool, len(t.Atomics)),
+		Outputs: make([]bool, len(t.columns)),
+	}
+	// For compiled columns, words holds word w of each column, which is computed once for its 64 rows.
+	words := make([]uint64, len(t.words))
+	w := ^uint64(0)
+	return t.rows(func(truth Truth) error {
+		for i, name := range t.Atomics {
+			row.Inputs[i], _ = truth.Get(name)
+		}
+		if t.words != nil && truth.Val/64 != w {
+			w = truth.Val / 64
+			for i, f := range t.words {
+				words[i] = f(w)
+			}
+		}
+		for i, column := range t.columns {
+			if t.words != nil {
+				row.Outputs[i] = words[i]&(1<<(truth.Val%64)) > 0
+			} else {
+				row.Outputs[i] = column.Eval(truth)
+			}
+		}
+		row.Differs = t.diff != nil && t.diff(truth)
+		return f(row)
+	})
+}
+
+// subexpressions returns the distinct compound subexpressions of the given Stmts in post-order, with each Stmt
+// following its own subexpressions (even if it is not compound).
+func subexpressions(stmts ...Stmt) []Stmt {
+	var subs []Stmt
+	seen := make(map[Stmt]bool)
+	add := func(s Stmt) {
+		if !seen[s] {
+			seen[s] = true
+			subs = append(subs, s)
+		}
+	}
+	for _, stmt := range stmts {
+		Rewrite(stmt, func(s Stmt) Stmt {
+			switch s.(type) {
+			case Var, Const:
+			default:
+				add(s)
+			}
+			return s
+		})
+		add(stmt)
+	}
+	return subs
+}
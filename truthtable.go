@@ -1,14 +1,14 @@
 package vera
 
 import (
-	"errors"
 	"fmt"
 	"github.com/fatih/color"
 	"io"
 	"strings"
+	"unicode/utf8"
 )
 
-// CharSet is a set of characters for rendering a table via RenderTT.
+// CharSet is a set of characters for rendering a table via RenderTT or BoxRenderer.
 type CharSet struct {
 	RowSep   string
 	ColSep   string
@@ -55,116 +55,186 @@ var ASCIIBoxCS = &CharSet{
 
 // TODO: improve customizability
 
+// TTOptions configures RenderTTWithOptions, RenderMultiTT, and NewTable. The zero value is the configuration used by
+// RenderTT.
+type TTOptions struct {
+	// Steps adds an output column for each distinct compound subexpression of the Stmt before the column for the Stmt
+	// itself. The columns are in post-order, so each one only depends on columns to its left.
+	Steps bool
+	// Diff adds a column to tables of several Stmts which marks the rows where the Stmts do not all have the same
+	// value. It has no effect on tables of a single Stmt.
+	Diff bool
+}
+
 // RenderTT writes a truth table for the given Stmt/Truth pair to the given io.Writer. The appearance of the table is
 // dictated by the given CharSet and colorize parameter.
 func RenderTT(stmt Stmt, truth Truth, out io.Writer, cs *CharSet, colorize bool) error {
-	color.NoColor = !colorize
-	if len(truth.Names) == 0 {
-		return errors.New("cannot make a truth table with no atomics")
+	return RenderTTWithOptions(stmt, truth, out, cs, colorize, TTOptions{})
+}
+
+// RenderTTWithOptions is like RenderTT, but allows the contents of the table to be configured with the given TTOptions.
+func RenderTTWithOptions(stmt Stmt, truth Truth, out io.Writer, cs *CharSet, colorize bool, opts TTOptions) error {
+	table, err := NewTable([]Stmt{stmt}, truth, opts)
+	if err != nil {
+		return err
 	}
-	stmtStr := stmt.String()
-	if err := printTopLine(len(truth.Names), len(stmtStr), out, cs); err != nil {
+	return BoxRenderer{cs, colorize}.Render(table, out)
+}
+
+// RenderMultiTT writes a truth table in the same style as RenderTT with an output column for each of the given Stmts,
+// so that they can be compared side by side. The input columns are the union of the atomic statements in the Stmts (see
+// TruthFor).
+func RenderMultiTT(stmts []Stmt, out io.Writer, cs *CharSet, colorize bool, opts TTOptions) error {
+	table, err := NewTable(stmts, TruthFor(stmts...), opts)
+	if err != nil {
 		return err
 	}
-	if err := printHeader(truth.Names, stmtStr, out, cs); err != nil {
+	return BoxRenderer{cs, colorize}.Render(table, out)
+}
+
+// RenderRows writes a table in the same style as RenderTT, but with an output column for each of the given Stmts and
+// only the given rows of truth values (rather than every possible set of truth values). The input columns are the
+// union of the atomic statements in the Stmts (see TruthFor), so each row must have a truth value for each of them.
+// This is useful for displaying counterexamples and witnesses, e.g. those returned by Equivalent or ClassifyWitness.
+func RenderRows(stmts []Stmt, rows []Truth, out io.Writer, cs *CharSet, colorize bool) error {
+	return BoxRenderer{cs, colorize}.Render(NewRowsTable(stmts, rows), out)
+}
+
+// BoxRenderer is a Renderer which draws a table with the characters in CS, optionally coloring the truth values green
+// (for 1) and red (for 0). It is the Renderer used by RenderTT.
+type BoxRenderer struct {
+	CS       *CharSet
+	Colorize bool
+}
+
+// Render implements Renderer.
+func (r BoxRenderer) Render(t *Table, out io.Writer) error {
+	color.NoColor = !r.Colorize
+	headers := append([]string(nil), t.Outputs...)
+	if t.Diff {
+		headers = append(headers, diffHeader)
+	}
+	// The statements may contain non-ASCII operator symbols, so measure their widths in runes.
+	widths := make([]int, len(headers))
+	for i, header := range headers {
+		widths[i] = utf8.RuneCountInString(header)
+	}
+	if err := printTopLine(t.Atomics, widths, out, r.CS); err != nil {
 		return err
 	}
-	if err := printHeaderLine(len(truth.Names), len(stmtStr), out, cs); err != nil {
+	if err := printHeader(t.Atomics, headers, out, r.CS); err != nil {
 		return err
 	}
-	n := 1 << len(truth.Names)
-	for i := 0; i < n; i++ {
-		if err := printData(truth.Val, len(truth.Names), stmt.Eval(truth), len(stmtStr), out, cs); err != nil {
-			return err
-		}
-		truth.Val++
+	if err := printHeaderLine(t.Atomics, widths, out, r.CS); err != nil {
+		return err
 	}
-	if err := printBottomLine(len(truth.Names), len(stmtStr), out, cs); err != nil {
+	outputs := make([]string, len(widths))
+	err := t.EachRow(func(row TableRow) error {
+		for i, output := range row.Outputs {
+			outputs[i] = colorBool(output, widths[i])
+		}
+		if t.Diff {
+			outputs[len(row.Outputs)] = centerText("", len(diffHeader))
+			if row.Differs {
+				outputs[len(row.Outputs)] = color.YellowString(centerText("*", len(diffHeader)))
+			}
+		}
+		return printData(row.Inputs, t.Atomics, outputs, out, r.CS)
+	})
+	if err != nil {
 		return err
 	}
-	return nil
+	return printBottomLine(t.Atomics, widths, out, r.CS)
 }
 
 // printTopLine draws the top line in the table (i.e. above the header).
-func printTopLine(nAtomics int, outputWidth int, out io.Writer, cs *CharSet) error {
-	return printLine(nAtomics, outputWidth, out, cs.RowSep, cs.TLCorner, cs.TopT, cs.TRCorner)
+func printTopLine(atomics []string, outputWidths []int, out io.Writer, cs *CharSet) error {
+	return printLine(atomics, outputWidths, out, cs.RowSep, cs.TLCorner, cs.TopT, cs.TRCorner)
 }
 
 // printHeaderLine draws the line between the header and the data in the table.
-func printHeaderLine(nAtomics int, outputWidth int, out io.Writer, cs *CharSet) error {
-	return printLine(nAtomics, outputWidth, out, cs.RowSep, cs.LeftT, cs.Center, cs.RightT)
+func printHeaderLine(atomics []string, outputWidths []int, out io.Writer, cs *CharSet) error {
+	return printLine(atomics, outputWidths, out, cs.RowSep, cs.LeftT, cs.Center, cs.RightT)
 }
 
 // printBottomLine draws the bottom line in the table (i.e. below the data).
-func printBottomLine(nAtomics int, outputWidth int, out io.Writer, cs *CharSet) error {
-	return printLine(nAtomics, outputWidth, out, cs.RowSep, cs.BLCorner, cs.BottomT, cs.BRCorner)
+func printBottomLine(atomics []string, outputWidths []int, out io.Writer, cs *CharSet) error {
+	return printLine(atomics, outputWidths, out, cs.RowSep, cs.BLCorner, cs.BottomT, cs.BRCorner)
 }
 
-// calcInputWidth calculates the total width of all the input columns given the number of atomic statements.
-func calcInputWidth(nAtomics int) int {
-	return nAtomics + 2*(nAtomics-1)
+// calcInputWidth calculates the total width of all the input columns given the names of the atomic statements.
+func calcInputWidth(atomics []string) int {
+	if len(atomics) == 0 {
+		return 0
+	}
+	width := 2 * (len(atomics) - 1)
+	for _, name := range atomics {
+		width += len(name)
+	}
+	return width
 }
 
-func printLine(nAtomics int, outputWidth int, out io.Writer, rowSep string, l string, m string, r string) error {
-	_, err := fmt.Fprintf(out, "%s%s%s%s%s\n",
-		l,
-		strings.Repeat(rowSep, calcInputWidth(nAtomics)),
-		m,
-		strings.Repeat(rowSep, outputWidth),
-		r,
-	)
-	return err
-}
+func printLine(atomics []string, outputWidths []int, out io.Writer, rowSep string, l string, m string,
+	r string) error {
 
-// printHeader prints the header, consisting of the names of the atomic statements and a nicely-formatted version of the
-// original input statement.
-func printHeader(atomics []byte, stmt string, out io.Writer, cs *CharSet) error {
 	var sb strings.Builder
-	sb.Grow(calcInputWidth(len(atomics)))
-	for i := len(atomics) - 1; i >= 0; i-- {
-		sb.WriteByte(atomics[i])
-		if i > 0 {
-			sb.WriteString("  ")
-		}
+	sb.WriteString(l)
+	sb.WriteString(strings.Repeat(rowSep, calcInputWidth(atomics)))
+	for _, w := range outputWidths {
+		sb.WriteString(m)
+		sb.WriteString(strings.Repeat(rowSep, w))
 	}
-	return printRow(sb.String(), stmt, out, cs)
+	sb.WriteString(r)
+	_, err := fmt.Fprintln(out, sb.String())
+	return err
 }
 
-// centerText centers the given ASCII string in spaces such that the returned string has length >= width.
+// printHeader prints the header, consisting of the names of the atomic statements and nicely-formatted versions of the
+// output statements.
+func printHeader(atomics []string, stmts []string, out io.Writer, cs *CharSet) error {
+	return printRow(strings.Join(atomics, "  "), stmts, out, cs)
+}
+
+// centerText centers the given string in spaces such that the returned string is at least width runes wide.
 func centerText(text string, width int) string {
-	// Assumes text is ASCII.
-	return fmt.Sprintf("%[1]*s", -width, fmt.Sprintf("%[1]*s", (width+len(text))/2, text))
+	// Assumes each rune in text occupies a single column.
+	n := utf8.RuneCountInString(text)
+	if n >= width {
+		return text
+	}
+	left := (width - n) / 2
+	return strings.Repeat(" ", left) + text + strings.Repeat(" ", width-n-left)
+}
+
+// colorBool returns "1" or "0" centered to the given width and colored green or red respectively.
+func colorBool(b bool, width int) string {
+	if b {
+		return color.GreenString(centerText("1", width))
+	}
+	return color.RedString(centerText("0", width))
 }
 
-// printData prints a single row of truth values and their associated output.
-func printData(truth uint64, nAtomics int, output bool, outputWidth int, out io.Writer, cs *CharSet) error {
+// printData prints a single row of truth values and their associated (already formatted) outputs. Each truth value is
+// centered under the name of its atomic statement.
+func printData(inputs []bool, atomics []string, outputs []string, out io.Writer, cs *CharSet) error {
 	var sb strings.Builder
-	for i := nAtomics - 1; i >= 0; i-- {
-		if truth&(1<<i) > 0 {
-			sb.WriteString(color.GreenString("1"))
-		} else {
-			sb.WriteString(color.RedString("0"))
-		}
+	for i, v := range inputs {
 		if i > 0 {
 			sb.WriteString("  ")
 		}
+		sb.WriteString(colorBool(v, len(atomics[i])))
 	}
-	var outputStr string
-	if output {
-		outputStr = color.GreenString(centerText("1", outputWidth))
-	} else {
-		outputStr = color.RedString(centerText("0", outputWidth))
-	}
-	return printRow(sb.String(), outputStr, out, cs)
+	return printRow(sb.String(), outputs, out, cs)
 }
 
-func printRow(input string, output string, out io.Writer, cs *CharSet) error {
+func printRow(input string, outputs []string, out io.Writer, cs *CharSet) error {
 	var sb strings.Builder
-	sb.Grow(3 + len(input) + len(output))
 	sb.WriteString(cs.ColSep)
 	sb.WriteString(input)
-	sb.WriteString(cs.ColSep)
-	sb.WriteString(output)
+	for _, output := range outputs {
+		sb.WriteString(cs.ColSep)
+		sb.WriteString(output)
+	}
 	sb.WriteString(cs.ColSep)
 	_, err := fmt.Fprintln(out, sb.String())
 	return err
@@ -0,0 +1,110 @@
+package vera
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderTT(t *testing.T) {
+	stmt, truth, err := Parse("a & bb")
+	if err != nil {
+		t.Fatalf("error occurred while parsing: %v", err)
+	}
+	var sb strings.Builder
+	if err := RenderTT(stmt, truth, &sb, ASCIIBoxCS, false); err != nil {
+		t.Fatalf("error occurred while rendering: %v", err)
+	}
+	expected := `+-----+------+
+|a  bb|a & bb|
++-----+------+
+|0  0 |  0   |
+|0  1 |  0   |
+|1  0 |  0   |
+|1  1 |  1   |
++-----+------+
+`
+	if sb.String() != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, sb.String())
+	}
+}
+
+func TestRenderRows(t *testing.T) {
+	a, _, err := Parse("a > b")
+	if err != nil {
+		t.Fatalf("error occurred while parsing: %v", err)
+	}
+	b, _, err := Parse("b ↑ c")
+	if err != nil {
+		t.Fatalf("error occurred while parsing: %v", err)
+	}
+	row := TruthFor(a, b)
+	row.Set("b", true)
+	row.Set("c", true)
+	var sb strings.Builder
+	if err := RenderRows([]Stmt{a, b}, []Truth{row}, &sb, PrettyBoxCS, false); err != nil {
+		t.Fatalf("error occurred while rendering: %v", err)
+	}
+	expected := `┌───────┬─────┬─────┐
+│a  b  c│a > b│b ↑ c│
+├───────┼─────┼─────┤
+│0  1  1│  1  │  0  │
+└───────┴─────┴─────┘
+`
+	if sb.String() != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, sb.String())
+	}
+}
+
+func TestRenderTTSteps(t *testing.T) {
+	stmt, truth, err := Parse("(!(p > q) | r) & (p > q)")
+	if err != nil {
+		t.Fatalf("error occurred while parsing: %v", err)
+	}
+	var sb strings.Builder
+	if err := RenderTTWithOptions(stmt, truth, &sb, ASCIIBoxCS, false, TTOptions{Steps: true}); err != nil {
+		t.Fatalf("error occurred while rendering: %v", err)
+	}
+	expected := `+-------+-----+--------+------------+------------------------+
+|p  q  r|p > q|!(p > q)|!(p > q) | r|(!(p > q) | r) & (p > q)|
++-------+-----+--------+------------+------------------------+
+|0  0  0|  1  |   0    |     0      |           0            |
+|0  0  1|  1  |   0    |     1      |           1            |
+|0  1  0|  1  |   0    |     0      |           0            |
+|0  1  1|  1  |   0    |     1      |           1            |
+|1  0  0|  0  |   1    |     1      |           0            |
+|1  0  1|  0  |   1    |     1      |           0            |
+|1  1  0|  1  |   0    |     0      |           0            |
+|1  1  1|  1  |   0    |     1      |           1            |
++-------+-----+--------+------------+------------------------+
+`
+	if sb.String() != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, sb.String())
+	}
+}
+
+func TestRenderMultiTT(t *testing.T) {
+	var stmts []Stmt
+	for _, input := range []string{"a > b", "!a | b", "b > a"} {
+		stmt, _, err := Parse(input)
+		if err != nil {
+			t.Fatalf("error occurred while parsing: %v (input: %s)", err, input)
+		}
+		stmts = append(stmts, stmt)
+	}
+	var sb strings.Builder
+	if err := RenderMultiTT(stmts, &sb, ASCIIBoxCS, false, TTOptions{Diff: true}); err != nil {
+		t.Fatalf("error occurred while rendering: %v", err)
+	}
+	expected := `+----+-----+------+-----+----+
+|a  b|a > b|!a | b|b > a|diff|
++----+-----+------+-----+----+
+|0  0|  1  |  1   |  1  |    |
+|0  1|  1  |  1   |  0  | *  |
+|1  0|  0  |  0   |  1  | *  |
+|1  1|  1  |  1   |  1  |    |
++----+-----+------+-----+----+
+`
+	if sb.String() != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, sb.String())
+	}
+}
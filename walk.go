@@ -0,0 +1,69 @@
+package vera
+
+import "fmt"
+
+// A Visitor's Visit method is invoked for each Stmt encountered by WalkVisitor. If the result visitor w is not nil,
+// WalkVisitor visits each of the children of the Stmt with the visitor w, followed by a call of w.Visit(nil).
+type Visitor interface {
+	Visit(s Stmt) (w Visitor)
+}
+
+// WalkVisitor traverses a Stmt tree in depth-first order: it starts by calling v.Visit(s); s must not be nil. If the
+// visitor w returned by v.Visit(s) is not nil, WalkVisitor is invoked recursively with visitor w for each of the
+// non-nil children of s, followed by a call of w.Visit(nil).
+func WalkVisitor(v Visitor, s Stmt) {
+	if v = v.Visit(s); v == nil {
+		return
+	}
+	switch s := s.(type) {
+	case Const, Var:
+		// Nothing to do.
+	case Not:
+		WalkVisitor(v, s.X)
+	case Binary:
+		WalkVisitor(v, s.Left)
+		WalkVisitor(v, s.Right)
+	case IfThenElse:
+		WalkVisitor(v, s.Cond)
+		WalkVisitor(v, s.Then)
+		WalkVisitor(v, s.Else)
+	default:
+		panic(fmt.Sprintf("vera.WalkVisitor: unexpected Stmt type %T", s))
+	}
+	v.Visit(nil)
+}
+
+// inspector adapts a function to the Visitor interface for Walk.
+type inspector func(Stmt) bool
+
+func (f inspector) Visit(s Stmt) Visitor {
+	if f(s) {
+		return f
+	}
+	return nil
+}
+
+// Walk traverses a Stmt tree in depth-first order: it starts by calling f(s); s must not be nil. If f returns true,
+// Walk invokes f recursively for each of the children of s, followed by a call of f(nil).
+func Walk(s Stmt, f func(Stmt) bool) {
+	WalkVisitor(inspector(f), s)
+}
+
+// Rewrite traverses a Stmt tree bottom-up, replacing each Stmt with the result of calling f on it, and returns the
+// rewritten tree. The children of a Stmt are rewritten before f is called on it, so f sees a Stmt whose children have
+// already been replaced. f should return its argument to leave a Stmt unchanged; it must not return nil. The original
+// tree is not modified.
+func Rewrite(s Stmt, f func(Stmt) Stmt) Stmt {
+	switch s := s.(type) {
+	case Const, Var:
+		return f(s)
+	case Not:
+		return f(Not{Rewrite(s.X, f)})
+	case Binary:
+		return f(Binary{s.Op, Rewrite(s.Left, f), Rewrite(s.Right, f)})
+	case IfThenElse:
+		return f(IfThenElse{Rewrite(s.Cond, f), Rewrite(s.Then, f), Rewrite(s.Else, f)})
+	default:
+		panic(fmt.Sprintf("vera.Rewrite: unexpected Stmt type %T", s))
+	}
+}
@@ -0,0 +1,80 @@
+package vera
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWalk(t *testing.T) {
+	stmt, _, err := Parse("!(a & b) > if c then a else 0")
+	if err != nil {
+		t.Fatalf("error occurred while parsing: %v", err)
+	}
+	var visited []string
+	Walk(stmt, func(s Stmt) bool {
+		if s == nil {
+			visited = append(visited, ")")
+			return false
+		}
+		visited = append(visited, s.String())
+		// Do not descend into negations.
+		_, ok := s.(Not)
+		return !ok
+	})
+	expected := "!(a & b) > (if c then a else 0)|!(a & b)|if c then a else 0|c|)|a|)|0|)|)|)"
+	if got := strings.Join(visited, "|"); got != expected {
+		t.Fatalf("expected %s; got %s", expected, got)
+	}
+}
+
+// countVisitor counts the number of Vars in a Stmt.
+type countVisitor struct {
+	n int
+}
+
+func (v *countVisitor) Visit(s Stmt) Visitor {
+	if _, ok := s.(Var); ok {
+		v.n++
+	}
+	return v
+}
+
+func TestWalkVisitor(t *testing.T) {
+	stmt, _, err := Parse("(a & b) | (a ^ !c)")
+	if err != nil {
+		t.Fatalf("error occurred while parsing: %v", err)
+	}
+	v := &countVisitor{}
+	WalkVisitor(v, stmt)
+	if v.n != 4 {
+		t.Fatalf("expected 4 Vars; got %d", v.n)
+	}
+}
+
+func TestRewrite(t *testing.T) {
+	stmt, _, err := Parse("(a > b) | !(c > a)")
+	if err != nil {
+		t.Fatalf("error occurred while parsing: %v", err)
+	}
+	// Substitute d for a and eliminate implications.
+	rewritten := Rewrite(stmt, func(s Stmt) Stmt {
+		switch s := s.(type) {
+		case Var:
+			if s == "a" {
+				return Var("d")
+			}
+		case Binary:
+			if s.Op == OpCond {
+				return Binary{OpOr, Not{s.Left}, s.Right}
+			}
+		}
+		return s
+	})
+	expected := "(!d | b) | !(!c | d)"
+	if got := rewritten.String(); got != expected {
+		t.Fatalf("expected %s; got %s", expected, got)
+	}
+	if got := stmt.String(); got != "(a > b) | !(c > a)" {
+		t.Fatalf("expected original statement to be unchanged; got %s", got)
+	}
+}